@@ -9,7 +9,6 @@ import (
 	"fmt"
 	"github.com/dark-enstein/chardot/internal/ilog"
 	"io"
-	"log"
 	"math"
 	"os"
 	"strings"
@@ -18,7 +17,7 @@ import (
 )
 
 var (
-	Clog = &ilog.Logger{} // Clog is the custom logger for the agent package.
+	Clog = &ilog.Logger{} // Clog is the custom logger for the agent package, and the one of A Hare given none.
 )
 
 const (
@@ -38,6 +37,9 @@ const (
 	RUN
 	TOTAL
 	ORIGIN
+	WAIT
+	REPLAY
+	GOTO
 )
 
 type Sign bool // Sign represents A boolean for positive (true) or negative (false) Sign.
@@ -47,18 +49,19 @@ const (
 	NEG Sign = false
 )
 
-// decideDirection decides the direction of displacement from Point p to Point q.
-func (d *displacement) decideDirection() {
-	if (d.p.X == 0 && d.p.Y == 0) && (d.q.X == 0 && d.q.Y == 0) {
-		Clog.Log(ilog.PANIC, "no Path, points referenced are nil: %v", d)
+// decideDirection decides the direction of displacement from Point p to Point q. It returns ErrNoDisplacement
+// when p and q are the same Point.
+func (d *displacement) decideDirection() error {
+	if d.p.X == d.q.X && d.p.Y == d.q.Y {
+		return fmt.Errorf("%w: from (%v, %v) to itself", ErrNoDisplacement, d.p.X, d.p.Y)
 	}
 
 	if d.p.X > d.q.X {
 		// EAST
-		if d.p.Y > d.q.Y {
+		if d.q.Y > d.p.Y {
 			// NORTHEAST
 			d.d = NORTHEAST
-		} else if d.q.Y > d.p.Y {
+		} else if d.p.Y > d.q.Y {
 			// SOUTHEAST
 			d.d = SOUTHEAST
 		} else {
@@ -67,10 +70,10 @@ func (d *displacement) decideDirection() {
 		}
 	} else if d.q.X > d.p.X {
 		// WEST
-		if d.p.Y > d.q.Y {
+		if d.q.Y > d.p.Y {
 			// NORTHWEST
 			d.d = NORTHWEST
-		} else if d.q.Y > d.p.Y {
+		} else if d.p.Y > d.q.Y {
 			// SOUTHWEST
 			d.d = SOUTHWEST
 		} else {
@@ -85,11 +88,12 @@ func (d *displacement) decideDirection() {
 			d.d = SOUTH
 		}
 	}
+	return nil
 }
 
 type axis string
 
-// Pace represents A unit of movement in A given direction. It is stateless, and it defines A magnitude of shift of Agent along one Direction. Designed to be only used once, and discarded. Either only Y or X can be set.
+// Pace represents A unit of movement in A given direction. It is stateless, and it defines A magnitude of shift of Agent along one Direction. Designed to be only used once, and discarded. Either only Y or X can be set, except for the intercardinal Directions, which set both.
 type Pace struct {
 	x, y Coordinate
 	d    Direction
@@ -102,24 +106,34 @@ func NewPace(dir Direction) *Pace {
 }
 
 // ScalarMove moves the referenced Pace object without altering the referenced Direction.
-// The function argument is A Coordinate.
-func (p *Pace) ScalarMove(d Coordinate) {
+// The function argument is A Coordinate. An error wrapping ErrUnknownDirection is returned, and the Pace left as is,
+// if the Direction of the Pace is not one it can move along.
+func (p *Pace) ScalarMove(d Coordinate) error {
 	switch p.d {
 	case FORWARD, NORTH:
-		Clog.Log(ilog.INFO, "since %s, incrementing by %v", p.d.String(), d.Int())
 		p.y += d
 	case BACKWARD, SOUTH:
-		Clog.Log(ilog.INFO, "since %s, decrementing by %v", p.d.String(), d.Int())
 		p.y -= d
 	case RIGHT, WEST:
-		Clog.Log(ilog.INFO, "since %s, incrementing by %v", p.d.String(), d.Int())
 		p.x += d
 	case LEFT, EAST:
-		Clog.Log(ilog.INFO, "since %s, decrementing by %v", p.d.String(), d.Int())
 		p.x -= d
+	case STILL:
+	case NORTHEAST, NORTHWEST, SOUTHEAST, SOUTHWEST:
+		ns, ew := p.d.Split()
+		y, x := NewPace(ns), NewPace(ew)
+		if err := y.ScalarMove(d); err != nil {
+			return err
+		}
+		if err := x.ScalarMove(d); err != nil {
+			return err
+		}
+		p.x += x.x
+		p.y += y.y
 	default:
-		Clog.Log(ilog.PANIC, "direction %v not recognized", p.d.String())
+		return fmt.Errorf("%w: cannot move along %v", ErrUnknownDirection, p.d)
 	}
+	return nil
 }
 
 // VectorMove moves the referenced Pace p1 object while considering Direction.
@@ -133,22 +147,32 @@ func (p *Pace) ScalarMove(d Coordinate) {
 //	}
 //}
 
-func (p *Pace) Result() Coordinate {
+// Result returns the shift the Pace carries along its Direction. Intercardinal Paces shift equally along both
+// axes, so the magnitude of that shift is returned; use PMap to get each signed component. An error wrapping
+// ErrUnknownDirection is returned for A Direction that is not accounted for.
+func (p *Pace) Result() (Coordinate, error) {
 	switch p.d {
 	case FORWARD, BACKWARD, YDIRECTION, NORTH, SOUTH:
-		return p.y
+		return p.y, nil
 	case LEFT, RIGHT, XDIRECTION, EAST, WEST:
-		return p.x
-	default:
-		Clog.Log(ilog.PANIC, "Direction %v not accounted for", p.d.String())
-		//panic("Direction not accounted for")
+		return p.x, nil
+	case NORTHEAST, NORTHWEST, SOUTHEAST, SOUTHWEST:
+		return p.y.abs(), nil
+	case STILL:
+		return Coordinate(0), nil
 	}
-	return Coordinate(0)
+	return Coordinate(0), fmt.Errorf("%w: %v not accounted for", ErrUnknownDirection, p.d)
 }
 
+// PMap returns the Pace as A PMap. Intercardinal Paces are split into their two cardinal components. A Pace of an
+// unknown Direction maps to A zero shift along it.
 func (p *Pace) PMap() *PMap {
+	if p.d.IsDiagonal() {
+		ns, ew := p.d.Split()
+		return &PMap{ns: p.y, ew: p.x}
+	}
 	var pm = make(PMap, 1)
-	pm[p.d] = p.Result()
+	pm[p.d], _ = p.Result()
 	return &pm
 }
 
@@ -158,24 +182,35 @@ func (p *Pace) Point() *Point {
 
 type PMap map[Direction]Coordinate
 
+// Pace rebuilds the Pace A PMap was made from. A PMap holding both A north/south and an east/west component
+// yields the matching intercardinal Pace.
 func (pm *PMap) Pace() *Pace {
 	var p Pace
+	var ns, ew = Direction(-1), Direction(-1)
 	for k, v := range *pm {
 		p.d = k
 		switch k {
-		case FORWARD, BACKWARD, YDIRECTION:
+		case FORWARD, BACKWARD, YDIRECTION, NORTH, SOUTH:
 			p.y = v
-		case LEFT, RIGHT, XDIRECTION:
+			ns = k
+		case LEFT, RIGHT, XDIRECTION, EAST, WEST:
 			p.x = v
+			ew = k
 		}
 	}
+	if diag, ok := Intercardinal(ns, ew); ok {
+		p.d = diag
+	}
 	return &p
 }
 
 // Path describes the change in an Agent Point value. It is A more detailed Point. It is A collection of pace.
+// M, A and S hold the same paces: S[i] is A[i] with the time it was taken at and the movement that took it. S is
+// left empty by the Paths that are not recorded by an agent, such as Point.Path.
 type Path struct {
 	M   []PMap
 	A   []Pace
+	S   []Step
 	ctx context.Context
 }
 
@@ -186,7 +221,16 @@ func NewPath(len int) *Path {
 	}
 }
 
-func (p1 *Pace) displacement(p2 *Pace) *Point {
+// Copy returns A copy of the Path that shares nothing with it.
+func (p *Path) Copy() *Path {
+	return &Path{
+		M: append([]PMap(nil), p.M...),
+		A: append([]Pace(nil), p.A...),
+		S: append([]Step(nil), p.S...),
+	}
+}
+
+func (p1 *Pace) displacement(p2 *Pace) (*Point, error) {
 	switch p1.d {
 	case BACKWARD, LEFT:
 		switch p2.d {
@@ -197,13 +241,13 @@ func (p1 *Pace) displacement(p2 *Pace) *Point {
 			return &Point{
 				X: xway,
 				Y: yway,
-			}
+			}, nil
 		case RIGHT, FORWARD:
 			xway, yway := p2.x-p1.x, p2.y-p1.y
 			return &Point{
 				X: xway,
 				Y: yway,
-			}
+			}, nil
 		}
 	case RIGHT, FORWARD:
 		switch p2.d {
@@ -214,18 +258,18 @@ func (p1 *Pace) displacement(p2 *Pace) *Point {
 			return &Point{
 				X: xway,
 				Y: yway,
-			}
+			}, nil
 		case BACKWARD, LEFT:
 			xway, yway := p1.x-p2.x, p1.y-p2.y
 			return &Point{
 				X: xway,
 				Y: yway,
-			}
+			}, nil
 		}
 
 	}
-	Clog.Log(ilog.PANIC, "error Dimensions %v not recognized", p1.d.String())
-	return &Point{}
+	Clog.Log(ilog.ERROR, "error Dimensions %v not recognized", p1.d.String())
+	return nil, fmt.Errorf("%w: cannot displace %v by %v", ErrUnknownDirection, p1.d, p2.d)
 }
 
 // Point is the change in the position of an Agent in A cycle. Both X and Y can be changed at once.
@@ -247,103 +291,198 @@ type Config struct {
 	ctx       context.Context
 }
 
+// Hare is the Agent of the package. A Hare is safe for concurrent use: its position and Path can be read while it
+// is moving, and movements are queued and carried out one at a time, in the order they were started in.
 type Hare struct {
+	name      string
 	pos       Point
 	pathTaken *Path
 	allPos    []Point //stateful
 	nature    *Config
 	action    MovType
-	w         io.Writer
-	m         sync.Mutex
+	clock     Clock
+	reporter  Reporter
+	logger    *ilog.Logger  // logger is what the Hare logs to, Clog unless one is given
+	bounds    *Bounds       // bounds is the part of the world the Hare may move in, everywhere if nil
+	heading   Direction     // heading is the Direction the Hare last moved in
+	gait      Gait          // gait picks whether the Hare walks or runs each leg of a MoveTo
+	world     *World        // world is the grid the Hare moves over, an empty plane if nil
+	queue     []*job        // queue holds the movements waiting their turn
+	queued    int           // queued counts the movements ever queued, numbering them from 1
+	working   bool          // working is set while A worker goroutine drains the queue
+	gate      chan struct{} // gate is set while the Hare is paused, and closed when it resumes
+	listeners []*listener   // listeners are handed every Event of the Hare
+	m         sync.Mutex    // m guards the state of the Hare
 	ctx       context.Context
 }
 
-type Opts func()
-
-func NewHare(ctx context.Context, walk, run Speed) *Hare {
+// NewHare returns a Hare walking at walk and running at run Speed. Its logger, Clock and Reporter are taken from
+// ctx when it carries them, then opts are applied in order. An error is returned if an option is rejected, or if
+// the resulting Hare is not valid, such as one with a negative speed or starting out of its bounds.
+func NewHare(ctx context.Context, walk, run Speed, opts ...Opts) (*Hare, error) {
 	h := &Hare{
+		name:      AGENT,
 		pos:       Point{},
 		pathTaken: &Path{},
 		nature: &Config{
 			walk: walk,
 			run:  run,
 		},
-		w:   os.Stdout,
-		ctx: ctx,
+		clock:    RealClock{},
+		reporter: defaultReporter,
+		logger:   Clog,
+		heading:  NORTH,
+		gait:     WALKGAIT,
+		ctx:      ctx,
+	}
+	if logger, err := ilog.GetLoggerFromCtx(ctx); err == nil {
+		h.logger = logger
 	}
-	var err error
-	// sets global variable
-	Clog, err = ilog.GetLoggerFromCtx(ctx)
-	ilog.CheckErrLog(err)
-	printPathTaken(ORIGIN, nil, nil)
-	return h
+	if clock, err := GetClockFromCtx(ctx); err == nil {
+		h.clock = clock
+	}
+	if reporter, err := GetReporterFromCtx(ctx); err == nil {
+		h.reporter = reporter
+	}
+	if world, err := GetWorldFromCtx(ctx); err == nil {
+		h.world = world
+	}
+	for _, opt := range opts {
+		if err := opt(h); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.validate(); err != nil {
+		return nil, err
+	}
+	h.report(Report{Kind: REPORTORIGIN})
+	return h, nil
+}
+
+// Move displaces the Agent by x and y at once.
+func (h *Hare) Move(x, y Coordinate) error {
+	return h.MoveContext(h.ctx, x, y)
 }
 
-func (h *Hare) Move(x, y Coordinate) {
+// MoveContext is Move bound to ctx instead of the Hare's own context. Move is instantaneous, so it either happens
+// in full or, when ctx is already done, not at all.
+func (h *Hare) MoveContext(ctx context.Context, x, y Coordinate) error {
+	return h.MoveAsync(ctx, x, y).Wait()
+}
+
+// MoveAsync queues A Move bound to ctx and returns its Handle at once.
+func (h *Hare) MoveAsync(ctx context.Context, x, y Coordinate) *Handle {
+	return h.enqueue(MOVE, 1, func(hd *Handle) error {
+		return h.move(ctx, x, y, hd)
+	})
+}
+
+// move displaces the Hare by x and y, reporting the displacement to hd.
+func (h *Hare) move(ctx context.Context, x, y Coordinate, hd *Handle) error {
+	h.m.Lock()
+	if err := ctx.Err(); err != nil {
+		at := h.pos
+		h.m.Unlock()
+		return &PartialMoveError{Action: MOVE, Direction: Direction(-1), Total: 1, From: at, At: at, Err: err}
+	}
+	if to := (Point{X: h.pos.X + x, Y: h.pos.Y + y}); h.bounds != nil && !h.bounds.Contains(to) {
+		at := h.pos
+		h.m.Unlock()
+		return &PartialMoveError{Action: MOVE, Direction: Direction(-1), Total: 1, From: at, At: at, Err: ErrOutOfBounds}
+	}
+	// A Move jumps, so only where it lands has to be clear
+	if to := (Point{X: h.pos.X + x, Y: h.pos.Y + y}); h.world != nil && !h.world.Free(to) {
+		at, err := h.pos, ErrBlocked
+		if !h.world.Bounds.Contains(to) {
+			err = ErrOutOfBounds
+		}
+		h.m.Unlock()
+		return &PartialMoveError{Action: MOVE, Direction: Direction(-1), Total: 1, From: at, At: at, Err: err}
+	}
+	if d, ok := headingOf(x, y); ok {
+		h.heading = d
+	}
 	h.action = MOVE
-	log.Println("Set action to", h.action.String())
+	h.logger.Log(ilog.DEBUG, "Set action to %v", h.action.String())
 	var displace = &Point{
 		X: x,
 		Y: y,
 	}
-	log.Println("Registered displace directive as", displace)
+	h.logger.Log(ilog.DEBUG, "Registered displace directive as %v", displace)
+	start := h.pos
 	h.pos.X += x
 	h.pos.Y += y
 
 	h.allPos = append(h.allPos, h.pos)
 	var pos []Point
-	printPathTaken(h.action, displace.Path(), append(pos, h.pos))
-	h.Record(displace)
+	pos = append(pos, h.pos)
+	// A Move is instantaneous: each of its paces starts and ends at once, the second where the first left off
+	now, from := h.clock.Now(), start
+	var taken []Step
+	dist := displace.Path()
+	for i := range dist.A {
+		if dist.A[i].x == 0 && dist.A[i].y == 0 {
+			continue
+		}
+		to := Point{X: from.X + dist.A[i].x, Y: from.Y + dist.A[i].y}
+		taken = append(taken, h.recordStep(Step{Movement: hd.id, Start: now, End: now, Action: MOVE, Pace: dist.A[i], From: from, To: to}))
+		from = to
+	}
+	h.m.Unlock()
+	hd.advance(pos[0], taken...)
+	moved := &Path{}
+	for _, st := range taken {
+		moved.M = append(moved.M, *st.Pace.PMap())
+		moved.A = append(moved.A, st.Pace)
+		moved.S = append(moved.S, st)
+		h.emitPace(MOVE, st.Pace, st.To)
+	}
+	h.report(Report{Kind: REPORTEND, Action: MOVE, From: start, At: pos[0], Path: moved, Positions: pos})
+	return nil
 }
 
-func (h *Hare) RecordWithDirection(p *Pace) {
-	switch p.d {
-	case FORWARD, BACKWARD, YDIRECTION, NORTH, WEST:
-		h.rMap(0, p.y)
-		h.rArr(0, p.y)
-	case RIGHT, LEFT, XDIRECTION, SOUTH, EAST:
-		h.rMap(p.x, 0)
-		h.rArr(p.x, 0)
+// RecordWithDirection records A single Pace into the Path traveled thus far. A Pace of an unknown Direction is not
+// recorded, and an error wrapping ErrUnknownDirection is returned.
+func (h *Hare) RecordWithDirection(p *Pace) error {
+	if err := p.d.Validate(); err != nil {
+		return err
 	}
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.recordPace(p)
+	return nil
+}
+
+// recordPace records A single Pace, taken at once by the current action without moving the Hare. It must be
+// called with h.m held.
+func (h *Hare) recordPace(p *Pace) {
+	now := h.clock.Now()
+	h.recordStep(Step{Start: now, End: now, Action: h.action, Pace: *p, From: h.pos, To: h.pos})
 }
 
 // Record records the point taken and parses it into Path traveled thus far
 func (h *Hare) Record(d *Point) {
-	h.rMap(d.X, d.Y)
-	h.rArr(d.X, d.Y)
-}
-
-// rArr constructs a Pace object from two coordinates, and stores it in the *Path.A in the Hare struct
-func (h *Hare) rArr(x, y Coordinate) {
-	var arr = make([]Pace, Dimensions)
-	if x < 1 {
-		arr = append(arr)
-	} else if x > 0 {
-		arr = x
-	}
-
-	if y < 0 {
-		arr[BACKWARD] = y
-	} else if y > 0 {
-		arr[FORWARD] = y
-	}
-
-	for i := 0; i < len(AXES); i++ {
-		if AXES[i] == XAXIS {
-			pace := NewPace(XDIRECTION)
-			pace.ScalarMove(x)
-			h.pathTaken.A = append(h.pathTaken.A, *pace)
-		} else if AXES[i] == YAXIS {
-			pace := NewPace(YDIRECTION)
-			pace.ScalarMove(y)
-			h.pathTaken.A = append(h.pathTaken.A, *pace)
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.record(d)
+}
+
+// record parses the point taken into Paces and records them. It must be called with h.m held.
+func (h *Hare) record(d *Point) {
+	dist := d.Path()
+	for i := 0; i < len(dist.A); i++ {
+		if dist.A[i].x == 0 && dist.A[i].y == 0 {
+			continue
 		}
+		h.recordPace(&dist.A[i])
 	}
+}
 
+// rArr stores the Pace in the *Path.A in the Hare struct
+func (h *Hare) rArr(p *Pace) {
 	h.pathTaken.A = append(h.pathTaken.A, *p)
-
-	//h.pathTaken.A = append(h.pathTaken.A, arr...)
 }
+
 func (h *Hare) rMap(p *Pace) {
 	//cycle := make(map[Direction]Coordinate, Dimensions)
 	//if x < 0 {
@@ -358,12 +497,7 @@ func (h *Hare) rMap(p *Pace) {
 	//	cycle[FORWARD] = y
 	//}
 
-	switch p.d {
-	case FORWARD, BACKWARD, YDIRECTION, NORTH, WEST:
-		h.pathTaken.M = append(h.pathTaken.M, map[Direction]Coordinate{p.d: p.y})
-	case RIGHT, LEFT, XDIRECTION, SOUTH, EAST:
-		h.pathTaken.M = append(h.pathTaken.M, map[Direction]Coordinate{p.d: p.x})
-	}
+	h.pathTaken.M = append(h.pathTaken.M, *p.PMap())
 }
 
 //func travel() {}
@@ -373,153 +507,368 @@ func (h *Hare) rMap(p *Pace) {
 //
 // Arguments:
 //
+//	ctx context.Context: The context governing the movement. The Hare stops at the last completed pace once it is done.
 //	timeDur time.Duration: The duration of the movement.
 //	d Direction: The direction in which the Hare will move.
 //	s Speed: The speed at which the Hare moves.
+//	hd *Handle: The Handle every pace is reported to as it is taken.
 //
 // Returns:
 //
 //	[]Point: A slice of Point representing the positions of the Hare at each interval.
 //	*Path: A pointer to A Path struct that records the detailed Path taken.
+//	error: A *PartialMoveError if ctx ended the movement early, or an error if d is not A Direction the Hare can move in.
 //
 // The function works by calculating the number of paces (steps) the Hare can take
 // within the given time duration, considering its speed. It then moves the Hare step by step,
 // updating its position and recording each step in the Path. The function accounts for the
 // direction of movement and locks the Hare's position during updates to ensure thread safety.
-// It uses A goroutine to simulate the movement over time, and A timer to handle the duration.
+// Each pace waits one second (or what is left of timeDur) on the Hare's Clock, so A VirtualClock
+// drives the same paces without sleeping in real time.
 //
 // Note: This function is intended for internal use within the Hare struct to handle its movement
-// logic and should not be called directly from outside the package.
-func (h *Hare) flow(timeDur time.Duration, d Direction, s Speed) ([]Point, *Path) {
+// logic and should not be called directly from outside the package. It is only run by the queue worker.
+func (h *Hare) flow(ctx context.Context, timeDur time.Duration, d Direction, s Speed, hd *Handle) ([]Point, *Path, error) {
+	switch d {
+	case FORWARD, BACKWARD, NORTH, SOUTH, RIGHT, LEFT, EAST, WEST,
+		NORTHEAST, NORTHWEST, SOUTHEAST, SOUTHWEST, STILL:
+	default:
+		h.logger.Log(ilog.ERROR, "Invalid direction: %v", d)
+		return nil, nil, fmt.Errorf("%w: cannot travel %v", ErrUnknownDirection, d)
+	}
+	if s < 0 {
+		return nil, nil, fmt.Errorf("%w: %d is negative", ErrInvalidSpeed, s)
+	}
 	// noOfPaces to location in timeDur at d Direction and with s Speed.
-	noOfPaces := int(math.Ceil(timeDur.Seconds()))
+	noOfPaces := paces(timeDur)
 
 	endPosition := make([]Point, noOfPaces)
 	pathTaken := NewPath(noOfPaces)
-	timer := time.NewTimer(timeDur)
-	secT := time.NewTicker(time.Second)
-
-	go func() {
-		fmt.Println("Travelling...")
-		var i = 0
-		for range secT.C {
-			if i >= noOfPaces {
-				Clog.Log(ilog.DEBUG, "Index exceeds expected noOfPaces. Ending goroutine prematurely.")
-				break
-			}
-
-			t1 := time.Now()
-			h.m.Lock() // Lock the mutex before modifying h.pos
-			init := h.pos
-			var direcP *Coordinate
-			switch d {
-			case FORWARD, BACKWARD, NORTH, SOUTH:
-				direcP = &h.pos.Y
-			case RIGHT, LEFT, EAST, WEST:
-				direcP = &h.pos.X
-			default:
-				Clog.Log(ilog.ERROR, "Invalid direction: %v", d)
-				h.m.Unlock()
-				continue
-			}
-			*direcP += s.Int()
-			pace := NewPace(d)
-			fmt.Printf("pace: %v, speed: %d\n", pace, s.Int())
-			pace.ScalarMove(s.Int())
-			h.RecordWithDirection(pace)
-			pathTaken.M[i] = *pace.PMap()
-			pathTaken.A[i] = *pace
-			endPosition[i] = h.pos
-			h.m.Unlock() // Unlock the mutex after the modification is done
-			Clog.Log(ilog.INFO, "Travelled in dur: %v\n", time.Now().Sub(t1))
-			Clog.Log(ilog.INFO, "Travelled in one sec from %v to %v\n", init, h.pos)
-			i++
+	pathTaken.S = make([]Step, noOfPaces)
+	remaining := timeDur
+	from := h.Position()
+
+	h.report(Report{Kind: REPORTSTART, Action: h.action, From: from})
+	for i := 0; i < noOfPaces; i++ {
+		tick := time.Second
+		if remaining < tick {
+			tick = remaining
 		}
-
-		if i < noOfPaces {
-			Clog.Log(ilog.ERROR, "Travel ended prematurely. Only completed %d out of %d paces.", i, noOfPaces)
+		pace := NewPace(d)
+		err := pace.ScalarMove(s.Int())
+		var st Step
+		if err == nil {
+			st, err = h.takePace(ctx, pace, s, tick, hd)
 		}
-	}()
-wait:
-	for {
-		select {
-		case <-timer.C:
-			Clog.Log(ilog.DEBUG, "Timer completed, stopping ticker")
-			secT.Stop()
-			timer.Stop()
-			break wait
+		if err != nil {
+			h.logger.Log(ilog.ERROR, "Travel ended prematurely. Only completed %d out of %d paces.", i, noOfPaces)
+			return endPosition[:i], &Path{M: pathTaken.M[:i], A: pathTaken.A[:i], S: pathTaken.S[:i]}, &PartialMoveError{
+				Action:    h.action,
+				Direction: d,
+				Completed: i,
+				Total:     noOfPaces,
+				From:      from,
+				At:        h.Position(),
+				Err:       err,
+			}
 		}
+		remaining -= tick
+		pathTaken.M[i] = *pace.PMap()
+		pathTaken.A[i] = *pace
+		pathTaken.S[i] = st
+		endPosition[i] = st.To
+	}
+	return endPosition, pathTaken, nil
+}
+
+// takePace takes pace at Speed s, waiting tick on the Clock for it, and records it as a Step of the current action
+// of the Hare, on behalf of hd. It returns the Step, or an error if the pace would leave the bounds of the Hare or
+// ctx ended before it was taken, in which case the Hare is left in place.
+func (h *Hare) takePace(ctx context.Context, pace *Pace, s Speed, tick time.Duration, hd *Handle) (Step, error) {
+	if err := h.within(pace); err != nil {
+		return Step{}, err
+	}
+	start, err := h.step(ctx, tick)
+	if err != nil {
+		return Step{}, err
+	}
+
+	end := h.clock.Now()
+	h.m.Lock() // Lock the mutex before modifying h.pos
+	init := h.pos
+	if pace.d != STILL {
+		h.heading = pace.d
+	}
+	h.pos.X += pace.x
+	h.pos.Y += pace.y
+	h.allPos = append(h.allPos, h.pos)
+	st := h.recordStep(Step{Movement: hd.id, Start: start, End: end, Action: h.action, Speed: s, Pace: *pace, From: init, To: h.pos})
+	hd.advance(h.pos, st)
+	h.m.Unlock() // Unlock the mutex after the modification is done
+	h.report(Report{Kind: REPORTPACE, Action: st.Action, Speed: s, Pace: *pace, At: st.To})
+	h.emitPace(st.Action, *pace, st.To)
+	h.logger.Log(ilog.DEBUG, "Travelled from %v to %v in %v", init, st.To, st.Duration())
+	return st, nil
+}
+
+// within returns ErrOutOfBounds if taking pace would take the Hare out of its bounds or of its World, and
+// ErrBlocked if it would run into an obstacle of its World on the way.
+func (h *Hare) within(pace *Pace) error {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.bounds != nil && !h.bounds.Contains(Point{X: h.pos.X + pace.x, Y: h.pos.Y + pace.y}) {
+		return ErrOutOfBounds
+	}
+	if h.world != nil {
+		return h.world.check(h.pos, pace)
+	}
+	return nil
+}
+
+// headingOf returns the Direction of a displacement by x and y, following the axes Pace.ScalarMove moves along.
+// A displacement that goes nowhere has none.
+func headingOf(x, y Coordinate) (Direction, bool) {
+	ns, ew := Direction(-1), Direction(-1)
+	switch {
+	case y > 0:
+		ns = NORTH
+	case y < 0:
+		ns = SOUTH
+	}
+	switch {
+	case x > 0:
+		ew = WEST
+	case x < 0:
+		ew = EAST
+	}
+	if d, ok := Intercardinal(ns, ew); ok {
+		return d, true
+	}
+	if ns != Direction(-1) {
+		return ns, true
 	}
-	Println(0, "Travel complete")
-	return endPosition, pathTaken
+	return ew, ew != Direction(-1)
 }
 
+// step waits out A pace of tick on the Clock, after holding for as long as the Hare is paused. It returns the time
+// the pace started at, once the Hare was no longer paused.
+func (h *Hare) step(ctx context.Context, tick time.Duration) (time.Time, error) {
+	if err := h.hold(ctx); err != nil {
+		return time.Time{}, err
+	}
+	start := h.clock.Now()
+	return start, h.clock.Sleep(ctx, tick)
+}
+
+// paces returns the number of paces, one per started second, A movement lasting timeDur takes.
+func paces(timeDur time.Duration) int {
+	return int(math.Ceil(timeDur.Seconds()))
+}
+
+// Println prints the formatted line to os.Stdout, followed by rightSpacePadding blank lines. Agents do not use it;
+// they tell their Reporter instead.
 func Println(rightSpacePadding int, format string, args ...interface{}) {
-	fmt.Fprint(os.Stdout, fmt.Sprintf(format+strings.Repeat("\n", rightSpacePadding)+"\n", args...))
-	return
+	fprintln(os.Stdout, rightSpacePadding, format, args...)
+}
+
+// fprintln writes the formatted line to w, followed by rightSpacePadding blank lines.
+func fprintln(w io.Writer, rightSpacePadding int, format string, args ...interface{}) {
+	fmt.Fprint(w, fmt.Sprintf(format+strings.Repeat("\n", rightSpacePadding)+"\n", args...))
+}
+
+// report hands r to the Reporter of the Hare, stamped with its name and the time on its Clock. It must be called
+// without h.m held.
+func (h *Hare) report(r Report) {
+	h.m.Lock()
+	r.Agent = h.name
+	if r.Kind == REPORTORIGIN {
+		r.At = h.pos
+	}
+	h.m.Unlock()
+	r.Time = h.clock.Now()
+	h.reporter.Report(r)
 }
 
 // Walk moves the Agent by A specific magnitude, at A particular Direction and at its natural Speed
-func (h *Hare) Walk(duration time.Duration, dir Direction) {
-	h.action = WALK
-	former := h.pos
-	posStack, dist := h.flow(duration, dir, h.nature.walk)
-	Println(1, "Walked from %v to %v", former, h.pos)
-	fmt.Println(dist, posStack)
-	printPathTaken(h.action, dist, posStack)
-	//h.allPos, h.pathTaken.M, h.pathTaken.A = append(h.allPos, posStack...), append(h.pathTaken.M, dist.M...), append(h.pathTaken.A, dist.A...)
+func (h *Hare) Walk(duration time.Duration, dir Direction) error {
+	return h.WalkContext(h.ctx, duration, dir)
+}
+
+// WalkContext is Walk bound to ctx instead of the Hare's own context. If ctx is cancelled or its deadline passes,
+// the Hare stops at the last completed pace and A *PartialMoveError is returned.
+func (h *Hare) WalkContext(ctx context.Context, duration time.Duration, dir Direction) error {
+	return h.WalkAsync(ctx, duration, dir).Wait()
+}
+
+// WalkAsync queues A Walk bound to ctx and returns its Handle at once.
+func (h *Hare) WalkAsync(ctx context.Context, duration time.Duration, dir Direction) *Handle {
+	return h.enqueue(WALK, paces(duration), func(hd *Handle) error {
+		former := h.begin(WALK)
+		posStack, dist, err := h.flow(ctx, duration, dir, h.nature.walk, hd)
+		h.report(Report{Kind: REPORTEND, Action: WALK, From: former, At: h.Position(), Path: dist, Positions: posStack, Err: err})
+		return err
+	})
 }
 
 // Run moves the Agent by A specific magnitude, at A particular Direction and its natural running Speed
-func (h *Hare) Run(duration time.Duration, dir Direction) {
-	h.action = RUN
-	former := h.pos
-	posStack, dist := h.flow(duration, dir, h.nature.run)
-	Println(0, "Ran from %v to %v", former, h.pos)
-	printPathTaken(h.action, dist, posStack)
-	//h.allPos, h.pathTaken.M, h.pathTaken.A = append(h.allPos, posStack...), append(h.pathTaken.M, dist.M...), append(h.pathTaken.A, dist.A...)
+func (h *Hare) Run(duration time.Duration, dir Direction) error {
+	return h.RunContext(h.ctx, duration, dir)
 }
 
+// RunContext is Run bound to ctx instead of the Hare's own context. If ctx is cancelled or its deadline passes,
+// the Hare stops at the last completed pace and A *PartialMoveError is returned.
+func (h *Hare) RunContext(ctx context.Context, duration time.Duration, dir Direction) error {
+	return h.RunAsync(ctx, duration, dir).Wait()
+}
+
+// RunAsync queues A Run bound to ctx and returns its Handle at once.
+func (h *Hare) RunAsync(ctx context.Context, duration time.Duration, dir Direction) *Handle {
+	return h.enqueue(RUN, paces(duration), func(hd *Handle) error {
+		former := h.begin(RUN)
+		posStack, dist, err := h.flow(ctx, duration, dir, h.nature.run, hd)
+		h.report(Report{Kind: REPORTEND, Action: RUN, From: former, At: h.Position(), Path: dist, Positions: posStack, Err: err})
+		return err
+	})
+}
+
+// Wait keeps the Agent in place for A specific duration. The idle time is recorded in the Path as STILL paces,
+// one per second, so the Path stays aligned with the time spent.
+func (h *Hare) Wait(duration time.Duration) error {
+	return h.WaitContext(h.ctx, duration)
+}
+
+// WaitContext is Wait bound to ctx instead of the Hare's own context.
+func (h *Hare) WaitContext(ctx context.Context, duration time.Duration) error {
+	return h.WaitAsync(ctx, duration).Wait()
+}
+
+// WaitAsync queues A Wait bound to ctx and returns its Handle at once.
+func (h *Hare) WaitAsync(ctx context.Context, duration time.Duration) *Handle {
+	return h.enqueue(WAIT, paces(duration), func(hd *Handle) error {
+		at := h.begin(WAIT)
+		posStack, dist, err := h.flow(ctx, duration, STILL, 0, hd)
+		h.report(Report{Kind: REPORTEND, Action: WAIT, From: at, At: h.Position(), Path: dist, Positions: posStack, Err: err})
+		return err
+	})
+}
+
+// begin sets the action the Hare is carrying out and returns where it starts from.
+func (h *Hare) begin(action MovType) Point {
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.action = action
+	return h.pos
+}
+
+// Println reports the whole Path of the Hare and where it stands to its Reporter.
 func (h *Hare) Println() {
-	printPathTaken(TOTAL, h.pathTaken, h.allPos)
+	dist, allPos, at := h.Path(), h.Positions(), h.Position()
+	h.report(Report{Kind: REPORTSUMMARY, Path: dist, Positions: allPos, At: at})
+}
+
+// Name returns the name the Hare is known by, AGENT unless set otherwise.
+func (h *Hare) Name() string {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return h.name
+}
+
+// SetName renames the Hare.
+func (h *Hare) SetName(name string) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.name = name
 }
 
-// printPathTaken prints the Path taken in the current action (MovType instance), thus far.
+// Heading returns the Direction the Hare last moved in, or the one it was created facing.
+func (h *Hare) Heading() Direction {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return h.heading
+}
+
+// Bounds returns the part of the world the Hare may move in, and whether it is bounded at all.
+func (h *Hare) Bounds() (Bounds, bool) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.bounds == nil {
+		return Bounds{}, false
+	}
+	return *h.bounds, true
+}
+
+// Position returns the current position of the Hare.
+func (h *Hare) Position() Point {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return h.pos
+}
+
+// Positions returns A copy of every position the Hare has been at, in order.
+func (h *Hare) Positions() []Point {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return append([]Point(nil), h.allPos...)
+}
+
+// Path returns A copy of the Path traveled thus far.
+func (h *Hare) Path() *Path {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return h.pathTaken.Copy()
+}
+
+// fprintPathTaken writes the Path taken in the current action (MovType instance), thus far, to w.
 // It takes the current action, A pointer to the Path taken during the current action, and the position stack in the relevant action
-func printPathTaken(header MovType, dist *Path, allPos []Point) {
+func fprintPathTaken(w io.Writer, header MovType, dist *Path, allPos []Point) {
 	if dist == nil || allPos == nil {
-		fmt.Println(header)
+		fmt.Fprintln(w, header)
 		return
 	}
-	fmt.Println(header)
+	fmt.Fprintln(w, header)
 	switch true {
 	//case len(dist.A) != 0:
 	//	// logic for arr
 	case len(dist.M) != 0:
 		for i := 0; i < len(dist.M); i++ {
 			for k, v := range dist.M[i] {
+				v = v.abs()
 				switch k {
 				case FORWARD, NORTH:
-					fmt.Printf("MOVED FORWARD BY %v", v)
+					fmt.Fprintf(w, "MOVED FORWARD BY %v", v)
 				case BACKWARD, SOUTH:
-					fmt.Printf("MOVED BACKWARD BY %v", v)
+					fmt.Fprintf(w, "MOVED BACKWARD BY %v", v)
 				case RIGHT, WEST:
-					fmt.Printf("MOVED RIGHT BY %v", v)
+					fmt.Fprintf(w, "MOVED RIGHT BY %v", v)
 				case LEFT, EAST:
-					fmt.Printf("MOVED LEFT BY %v", v)
+					fmt.Fprintf(w, "MOVED LEFT BY %v", v)
+				case STILL:
+					fmt.Fprintf(w, "WAITED")
 				}
-				fmt.Print("; ")
+				fmt.Fprint(w, "; ")
 			}
 		}
-		fmt.Printf("\nCURRENT POS: \n\tX = %v \n\tY = %v\n\n", allPos[len(allPos)-1].X, allPos[len(allPos)-1].Y) // TODO: A bug
+		fmt.Fprintf(w, "\nCURRENT POS: \n\tX = %v \n\tY = %v\n\n", allPos[len(allPos)-1].X, allPos[len(allPos)-1].Y) // TODO: A bug
 	}
 }
 
 type Agent interface {
-	Move(x, y Coordinate)
+	Move(x, y Coordinate) error
 	Record(d *Point)
-	Walk(duration time.Duration, dir Direction)
-	Run(duration time.Duration, dir Direction)
+	Walk(duration time.Duration, dir Direction) error
+	Run(duration time.Duration, dir Direction) error
+	Wait(duration time.Duration) error
+}
+
+// ContextAgent is an Agent whose movements can be bound to A caller supplied context, so that they can be cancelled.
+type ContextAgent interface {
+	Agent
+	MoveContext(ctx context.Context, x, y Coordinate) error
+	WalkContext(ctx context.Context, duration time.Duration, dir Direction) error
+	RunContext(ctx context.Context, duration time.Duration, dir Direction) error
+	WaitContext(ctx context.Context, duration time.Duration) error
 }
 
 var AGENT = "agent" // AGENT represents the name of the agent, used in logging.
+
+var CLOCKCTX = "CLOCKCTX" // CLOCKCTX is the context key under which the Clock used by agents is stored.
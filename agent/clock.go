@@ -0,0 +1,204 @@
+package agent
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock abstracts the passage of time for an Agent. Movements wait on the Clock between paces,
+// so swapping the RealClock for a VirtualClock lets a scenario execute instantly and deterministically.
+type Clock interface {
+	// Now returns the current time according to the Clock.
+	Now() time.Time
+	// After waits for the duration to elapse on the Clock and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+	// Sleep blocks until the duration has elapsed on the Clock, or returns ctx.Err() as soon as ctx is done.
+	Sleep(ctx context.Context, d time.Duration) error
+}
+
+// RealClock is the wall clock. It is the default Clock of a Hare.
+type RealClock struct{}
+
+// Now returns time.Now.
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// After returns time.After.
+func (RealClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Sleep waits for d on a timer, or until ctx is done.
+func (RealClock) Sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// VirtualClock is a Clock that only moves when told to. Time is advanced manually with Advance, or
+// automatically once every participant registered through Join is blocked waiting on the clock. A VirtualClock
+// is safe for concurrent use.
+type VirtualClock struct {
+	now          time.Time
+	waiters      []*waiter
+	participants int
+	m            sync.Mutex
+	c            *sync.Cond
+}
+
+// waiter is a pending call to VirtualClock.After
+type waiter struct {
+	at time.Time
+	c  chan time.Time
+}
+
+// NewVirtualClock returns a VirtualClock whose current time is start.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	vc := &VirtualClock{now: start}
+	vc.c = sync.NewCond(&vc.m)
+	return vc
+}
+
+// Now returns the current virtual time.
+func (vc *VirtualClock) Now() time.Time {
+	vc.m.Lock()
+	defer vc.m.Unlock()
+	return vc.now
+}
+
+// After returns a channel that receives the virtual time once the clock has been advanced by d.
+// A non-positive d fires immediately.
+func (vc *VirtualClock) After(d time.Duration) <-chan time.Time {
+	vc.m.Lock()
+	defer vc.m.Unlock()
+	c := make(chan time.Time, 1)
+	if d <= 0 {
+		c <- vc.now
+		return c
+	}
+	vc.waiters = append(vc.waiters, &waiter{at: vc.now.Add(d), c: c})
+	vc.c.Broadcast()
+	vc.settle()
+	return c
+}
+
+// Sleep blocks until the clock has been advanced by d. If ctx is done first, the pending wait is withdrawn so it no
+// longer holds back participants, and ctx.Err() is returned.
+func (vc *VirtualClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	vc.m.Lock()
+	if d <= 0 {
+		vc.m.Unlock()
+		return nil
+	}
+	w := &waiter{at: vc.now.Add(d), c: make(chan time.Time, 1)}
+	vc.waiters = append(vc.waiters, w)
+	vc.c.Broadcast()
+	vc.settle()
+	vc.m.Unlock()
+
+	select {
+	case <-w.c:
+		return nil
+	case <-ctx.Done():
+		vc.withdraw(w)
+		return ctx.Err()
+	}
+}
+
+// withdraw removes a pending waiter.
+func (vc *VirtualClock) withdraw(w *waiter) {
+	vc.m.Lock()
+	defer vc.m.Unlock()
+	for i := range vc.waiters {
+		if vc.waiters[i] == w {
+			vc.waiters = append(vc.waiters[:i], vc.waiters[i+1:]...)
+			break
+		}
+	}
+	vc.c.Broadcast()
+}
+
+// Advance moves the clock forward by d, firing every waiter whose deadline falls within it, in deadline order.
+func (vc *VirtualClock) Advance(d time.Duration) {
+	vc.m.Lock()
+	defer vc.m.Unlock()
+	vc.advanceTo(vc.now.Add(d))
+}
+
+// Waiters returns the number of pending After calls.
+func (vc *VirtualClock) Waiters() int {
+	vc.m.Lock()
+	defer vc.m.Unlock()
+	return len(vc.waiters)
+}
+
+// BlockUntil blocks until at least n callers are waiting on the clock. It is useful to step a movement
+// pace by pace: wait for the Agent to block, then Advance.
+func (vc *VirtualClock) BlockUntil(n int) {
+	vc.m.Lock()
+	defer vc.m.Unlock()
+	for len(vc.waiters) < n {
+		vc.c.Wait()
+	}
+}
+
+// Join registers a participant. While there are participants, the clock jumps straight to the next deadline
+// as soon as every participant is waiting on it, so a batch of Agents sharing the clock never sleeps.
+func (vc *VirtualClock) Join() {
+	vc.m.Lock()
+	defer vc.m.Unlock()
+	vc.participants++
+}
+
+// Leave deregisters a participant previously registered with Join.
+func (vc *VirtualClock) Leave() {
+	vc.m.Lock()
+	defer vc.m.Unlock()
+	if vc.participants > 0 {
+		vc.participants--
+	}
+	vc.settle()
+}
+
+// settle advances the clock to the earliest deadline when every participant is blocked. It must be called with vc.m held.
+func (vc *VirtualClock) settle() {
+	if vc.participants == 0 || len(vc.waiters) < vc.participants {
+		return
+	}
+	earliest := vc.waiters[0].at
+	for _, w := range vc.waiters[1:] {
+		if w.at.Before(earliest) {
+			earliest = w.at
+		}
+	}
+	vc.advanceTo(earliest)
+}
+
+// advanceTo fires every waiter due at or before t and sets the clock to t. It must be called with vc.m held.
+func (vc *VirtualClock) advanceTo(t time.Time) {
+	if t.Before(vc.now) {
+		return
+	}
+	sort.SliceStable(vc.waiters, func(i, j int) bool {
+		return vc.waiters[i].at.Before(vc.waiters[j].at)
+	})
+	var i int
+	for i = 0; i < len(vc.waiters) && !vc.waiters[i].at.After(t); i++ {
+		vc.now = vc.waiters[i].at
+		vc.waiters[i].c <- vc.now
+	}
+	vc.waiters = vc.waiters[i:]
+	vc.now = t
+	vc.c.Broadcast()
+}
@@ -0,0 +1,162 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+)
+
+var epoch = time.Unix(0, 0)
+
+func TestVirtualClockAdvanceFiresInDeadlineOrder(t *testing.T) {
+	vc := agent.NewVirtualClock(epoch)
+	late, early, beyond := vc.After(3*time.Second), vc.After(time.Second), vc.After(10*time.Second)
+
+	vc.Advance(5 * time.Second)
+	if got := <-early; !got.Equal(epoch.Add(time.Second)) {
+		t.Errorf("early fired at %v, want %v", got, epoch.Add(time.Second))
+	}
+	if got := <-late; !got.Equal(epoch.Add(3 * time.Second)) {
+		t.Errorf("late fired at %v, want %v", got, epoch.Add(3*time.Second))
+	}
+	select {
+	case got := <-beyond:
+		t.Fatalf("a waiter due after the advance fired at %v", got)
+	default:
+	}
+	if got := vc.Now(); !got.Equal(epoch.Add(5 * time.Second)) {
+		t.Errorf("Now() = %v after Advance, want %v", got, epoch.Add(5*time.Second))
+	}
+	if n := vc.Waiters(); n != 1 {
+		t.Errorf("Waiters() = %d, want 1", n)
+	}
+}
+
+func TestVirtualClockAfterNonPositiveFiresAtOnce(t *testing.T) {
+	vc := agent.NewVirtualClock(epoch)
+	select {
+	case got := <-vc.After(0):
+		if !got.Equal(epoch) {
+			t.Errorf("After(0) fired at %v, want %v", got, epoch)
+		}
+	default:
+		t.Fatal("After(0) did not fire without an Advance")
+	}
+	if n := vc.Waiters(); n != 0 {
+		t.Errorf("Waiters() = %d, want 0", n)
+	}
+}
+
+func TestVirtualClockBlockUntil(t *testing.T) {
+	vc := agent.NewVirtualClock(epoch)
+	woke := make(chan time.Time, 2)
+	for _, d := range []time.Duration{2 * time.Second, time.Second} {
+		d := d
+		go func() {
+			if err := vc.Sleep(context.Background(), d); err != nil {
+				t.Error(err)
+			}
+			woke <- vc.Now()
+		}()
+	}
+
+	vc.BlockUntil(2)
+	if n := vc.Waiters(); n != 2 {
+		t.Fatalf("Waiters() = %d after BlockUntil(2), want 2", n)
+	}
+	vc.Advance(time.Second)
+	if got := <-woke; !got.Equal(epoch.Add(time.Second)) {
+		t.Errorf("first sleeper woke at %v, want %v", got, epoch.Add(time.Second))
+	}
+	vc.BlockUntil(1)
+	vc.Advance(time.Second)
+	if got := <-woke; !got.Equal(epoch.Add(2 * time.Second)) {
+		t.Errorf("second sleeper woke at %v, want %v", got, epoch.Add(2*time.Second))
+	}
+}
+
+func TestVirtualClockSettlesOnceEveryParticipantWaits(t *testing.T) {
+	vc := agent.NewVirtualClock(epoch)
+	var (
+		m     sync.Mutex
+		order []string
+		wg    sync.WaitGroup
+	)
+	sleep := func(name string, ds ...time.Duration) {
+		defer wg.Done()
+		defer vc.Leave()
+		for _, d := range ds {
+			if err := vc.Sleep(context.Background(), d); err != nil {
+				t.Error(err)
+				return
+			}
+			m.Lock()
+			order = append(order, name+"@"+vc.Now().Sub(epoch).String())
+			m.Unlock()
+		}
+	}
+	vc.Join()
+	vc.Join()
+	wg.Add(2)
+	go sleep("a", 3*time.Second, time.Second)
+	go sleep("b", 2*time.Second, 5*time.Second)
+	wg.Wait()
+
+	want := []string{"b@2s", "a@3s", "a@4s", "b@7s"}
+	if len(order) != len(want) {
+		t.Fatalf("woke %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("woke %v, want %v", order, want)
+		}
+	}
+	if got := vc.Now(); !got.Equal(epoch.Add(7 * time.Second)) {
+		t.Errorf("Now() = %v, want %v", got, epoch.Add(7*time.Second))
+	}
+}
+
+func TestVirtualClockSettleWaitsForEveryParticipant(t *testing.T) {
+	vc := agent.NewVirtualClock(epoch)
+	vc.Join()
+	vc.Join()
+	c := vc.After(time.Second)
+	select {
+	case <-c:
+		t.Fatal("the clock moved with one of two participants waiting")
+	default:
+	}
+	vc.Leave()
+	select {
+	case got := <-c:
+		if !got.Equal(epoch.Add(time.Second)) {
+			t.Errorf("fired at %v, want %v", got, epoch.Add(time.Second))
+		}
+	default:
+		t.Fatal("the clock did not move once the only other participant left")
+	}
+}
+
+func TestVirtualClockSleepWithdrawsOnCancel(t *testing.T) {
+	vc := agent.NewVirtualClock(epoch)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- vc.Sleep(ctx, time.Second)
+	}()
+	vc.BlockUntil(1)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Sleep() = %v, want context.Canceled", err)
+	}
+	if n := vc.Waiters(); n != 0 {
+		t.Errorf("Waiters() = %d after cancel, want 0", n)
+	}
+	if got := vc.Now(); !got.Equal(epoch) {
+		t.Errorf("Now() = %v, want %v", got, epoch)
+	}
+}
@@ -1,8 +1,6 @@
 package agent
 
 import (
-	"fmt"
-	"github.com/dark-enstein/chardot/internal/ilog"
 	"strconv"
 )
 
@@ -33,36 +31,34 @@ func (p *Coordinate) Sign() Sign {
 	return NEG
 }
 
-// Negate turns a positive Coordinate integer into a negative one
+// Negate turns a positive Coordinate integer into a negative one. A negative Coordinate is left as is.
+// The error result is deprecated: Negate cannot fail, and the error is always nil. Use MustNegate.
 func (p *Coordinate) Negate() error {
-	i, err := strconv.ParseInt(fmt.Sprintf("-%v", p), 10, 0)
-	if err != nil {
-		return ilog.CheckErrAll(err, "error occured while negating")()
-	}
-	p.assume(int(i))
+	p.MustNegate()
 	return nil
 }
 
-// MustNegate turns a positive Coordinate integer into a negative one
+// MustNegate turns a positive Coordinate integer into a negative one. A negative Coordinate is left as is.
 func (p *Coordinate) MustNegate() {
-	if err := p.Negate(); err != nil {
-		panic(err)
-	}
+	*p = -p.abs()
 }
 
-// Denegate turns a negative Coordinate integer into a positive one
+// Denegate turns a negative Coordinate integer into a positive one. A positive Coordinate is left as is.
+// The error result is deprecated: Denegate cannot fail, and the error is always nil. Use MustDenegate.
 func (p *Coordinate) Denegate() error {
-	i, err := strconv.ParseInt(fmt.Sprintf("%v", p)[1:], 10, 0)
-	if err != nil {
-		return ilog.CheckErrAll(err, "error occured while denegating")()
-	}
-	p.assume(int(i))
+	p.MustDenegate()
 	return nil
 }
 
-// MustDenegate turns a positive Coordinate integer into a negative one
+// MustDenegate turns a negative Coordinate integer into a positive one. A positive Coordinate is left as is.
 func (p *Coordinate) MustDenegate() {
-	if err := p.Denegate(); err != nil {
-		panic(err)
+	*p = p.abs()
+}
+
+// abs returns the magnitude of the Coordinate.
+func (p Coordinate) abs() Coordinate {
+	if p < 0 {
+		return -p
 	}
+	return p
 }
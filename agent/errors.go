@@ -0,0 +1,51 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnknownDirection is returned, wrapped, for a Direction the package does not know, or cannot move along.
+var ErrUnknownDirection = errors.New("unknown direction")
+
+// ErrInvalidSpeed is returned, wrapped, for a Speed an agent cannot move at, such as a negative one.
+var ErrInvalidSpeed = errors.New("invalid speed")
+
+// ErrNoDisplacement is returned by the displacement helpers when there is nothing to move by.
+var ErrNoDisplacement = errors.New("no displacement")
+
+// ErrOutOfBounds is the cause of a *PartialMoveError for a movement stopped at the edge of the bounds of its agent.
+var ErrOutOfBounds = errors.New("out of bounds")
+
+// ErrBlocked is the cause of a *PartialMoveError for a movement stopped before an obstacle of the World of its agent.
+var ErrBlocked = errors.New("blocked by an obstacle")
+
+// ErrNoRoute is returned, wrapped, when no route through the World of an agent leads where it was told to go.
+var ErrNoRoute = errors.New("no route")
+
+// ErrUnknownFormat is returned, wrapped, by Export and Import for a format they cannot write or read.
+var ErrUnknownFormat = errors.New("unknown format")
+
+// ErrInvalidRecording is returned, wrapped, for a Recording that cannot be imported or replayed.
+var ErrInvalidRecording = errors.New("invalid recording")
+
+// PartialMoveError is returned when a movement is interrupted by its context, by the edge of the bounds of its
+// agent, or by an obstacle, before it completes.
+// It describes how far the Agent got; the Agent is left at the last completed pace.
+type PartialMoveError struct {
+	Action    MovType
+	Direction Direction
+	Completed int   // Completed is the number of paces taken before the interruption.
+	Total     int   // Total is the number of paces the movement would have taken.
+	From, At  Point // From is where the movement started, At is where the Agent stopped.
+	Err       error // Err is the context error that interrupted the movement, ErrOutOfBounds or ErrBlocked.
+}
+
+func (e *PartialMoveError) Error() string {
+	return fmt.Sprintf("%v interrupted after %d of %d paces, stopped at (%v, %v): %v", e.Action, e.Completed, e.Total, e.At.X, e.At.Y, e.Err)
+}
+
+// Unwrap returns the cause, so errors.Is(err, context.Canceled) holds for a cancelled movement.
+func (e *PartialMoveError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,156 @@
+package agent
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType is the kind of an Event.
+type EventType int
+
+const (
+	ACTIONSTARTED   EventType = iota // ACTIONSTARTED is emitted when a movement leaves the queue and starts.
+	PACETAKEN                        // PACETAKEN is emitted for every pace a movement takes, STILL ones included.
+	POSITIONCHANGED                  // POSITIONCHANGED is emitted whenever a pace or a Move displaces the agent.
+	ACTIONFINISHED                   // ACTIONFINISHED is emitted when a movement ends, whether it completed or not.
+)
+
+// String returns the name of the EventType.
+func (e EventType) String() string {
+	switch e {
+	case ACTIONSTARTED:
+		return "ACTIONSTARTED"
+	case PACETAKEN:
+		return "PACETAKEN"
+	case POSITIONCHANGED:
+		return "POSITIONCHANGED"
+	case ACTIONFINISHED:
+		return "ACTIONFINISHED"
+	}
+	return "event type unrecognized"
+}
+
+// Event is something observable happening to an agent.
+type Event struct {
+	Type     EventType
+	Time     time.Time // Time is the time on the agent's Clock the Event happened at.
+	Agent    string    // Agent is the name of the agent.
+	Action   MovType   // Action is the movement the Event belongs to.
+	Pace     Pace      // Pace is the pace taken, for PACETAKEN and POSITIONCHANGED.
+	Position Point     // Position is where the agent is once the Event has happened.
+	Err      error     // Err is the error the movement ended with, for ACTIONFINISHED.
+}
+
+// Observable is implemented by agents that emit Events, such as Hare.
+type Observable interface {
+	Subscribe(fn func(Event)) (cancel func())
+	Events() (events <-chan Event, cancel func())
+}
+
+// listener is a function subscribed to the Events of a Hare.
+type listener struct {
+	fn func(Event)
+}
+
+// Subscribe registers fn to be called with every Event of the Hare, in order, until cancel is called. fn is called
+// from the goroutine carrying out the movement, before the movement goes on, so it must not block for long. It may
+// read from the Hare, but must not wait on a movement of the same Hare.
+func (h *Hare) Subscribe(fn func(Event)) (cancel func()) {
+	l := &listener{fn: fn}
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.listeners = append(h.listeners, l)
+	return func() {
+		h.m.Lock()
+		defer h.m.Unlock()
+		for i := range h.listeners {
+			if h.listeners[i] == l {
+				h.listeners = append(h.listeners[:i:i], h.listeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Events returns a channel receiving every Event of the Hare, in order, until cancel is called, after which the
+// channel is closed. Events are buffered for as long as the receiver lags behind, so a slow receiver never holds
+// the Hare back.
+func (h *Hare) Events() (events <-chan Event, cancel func()) {
+	var (
+		m       sync.Mutex
+		pending []Event
+		stopped bool
+	)
+	c := make(chan Event)
+	kick := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	unsubscribe := h.Subscribe(func(e Event) {
+		m.Lock()
+		defer m.Unlock()
+		if stopped {
+			return
+		}
+		pending = append(pending, e)
+		select {
+		case kick <- struct{}{}:
+		default:
+		}
+	})
+	go func() {
+		defer close(c)
+		for {
+			m.Lock()
+			batch := pending
+			pending = nil
+			m.Unlock()
+			for _, e := range batch {
+				select {
+				case c <- e:
+				case <-stop:
+					return
+				}
+			}
+			select {
+			case <-kick:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return c, func() {
+		once.Do(func() {
+			unsubscribe()
+			m.Lock()
+			stopped = true
+			m.Unlock()
+			close(stop)
+		})
+	}
+}
+
+// emit hands e to the listeners of the Hare, stamped with its name and the time on its Clock. It must be called
+// without h.m held.
+func (h *Hare) emit(e Event) {
+	h.m.Lock()
+	if len(h.listeners) == 0 {
+		h.m.Unlock()
+		return
+	}
+	e.Agent = h.name
+	listeners := append([]*listener(nil), h.listeners...)
+	h.m.Unlock()
+	e.Time = h.clock.Now()
+	for _, l := range listeners {
+		l.fn(e)
+	}
+}
+
+// emitPace emits the PACETAKEN Event of a pace that left the Hare at pos, followed by POSITIONCHANGED if the pace
+// displaced it.
+func (h *Hare) emitPace(action MovType, pace Pace, pos Point) {
+	h.emit(Event{Type: PACETAKEN, Action: action, Pace: pace, Position: pos})
+	if pace.x != 0 || pace.y != 0 {
+		h.emit(Event{Type: POSITIONCHANGED, Action: action, Pace: pace, Position: pos})
+	}
+}
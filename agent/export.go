@@ -0,0 +1,230 @@
+package agent
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+const (
+	JSONEXPORT    = "json"    // JSONEXPORT writes tracks as one JSON document, following the schema of EXPORTVERSION.
+	CSVEXPORT     = "csv"     // CSVEXPORT writes tracks as CSV, one row per pace.
+	GEOJSONEXPORT = "geojson" // GEOJSONEXPORT writes tracks as a GeoJSON FeatureCollection.
+)
+
+// EXPORTVERSION is the version of the schema JSONEXPORT writes. Fields may be added to it, but none are renamed or
+// removed without changing the version.
+const EXPORTVERSION = 1
+
+// Track is what is exported of an agent: its Path, every position it has been at, and where it stands.
+type Track struct {
+	Agent     string
+	Path      *Path
+	Positions []Point
+	At        Point
+}
+
+// Track returns the Track of the Hare thus far.
+func (h *Hare) Track() Track {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return Track{Agent: h.name, Path: h.pathTaken.Copy(), Positions: append([]Point(nil), h.allPos...), At: h.pos}
+}
+
+// Tracks returns the Track of every agent in the SimulationReport, in order of registration.
+func (r *SimulationReport) Tracks() []Track {
+	tracks := make([]Track, 0, len(r.Agents))
+	for _, ar := range r.Agents {
+		tracks = append(tracks, Track{Agent: ar.Name, Path: ar.Path, Positions: ar.Positions, At: ar.Position})
+	}
+	return tracks
+}
+
+// start returns where the Track starts: where its first Step does, or where the agent stands if it took none.
+func (t Track) start() Point {
+	if t.Path != nil {
+		if steps := t.Path.steps(); len(steps) > 0 {
+			return steps[0].From
+		}
+	}
+	return t.At
+}
+
+// steps returns the Steps of the Path of the Track.
+func (t Track) steps() []Step {
+	if t.Path == nil {
+		return nil
+	}
+	return t.Path.steps()
+}
+
+// Export writes the tracks to w in format, one of JSONEXPORT, CSVEXPORT and GEOJSONEXPORT. Any other format yields
+// an error wrapping ErrUnknownFormat.
+func Export(w io.Writer, format string, tracks ...Track) error {
+	switch format {
+	case JSONEXPORT:
+		return ExportJSON(w, tracks...)
+	case CSVEXPORT:
+		return ExportCSV(w, tracks...)
+	case GEOJSONEXPORT:
+		return ExportGeoJSON(w, tracks...)
+	}
+	return fmt.Errorf("%w: %q, use %q, %q or %q", ErrUnknownFormat, format, JSONEXPORT, CSVEXPORT, GEOJSONEXPORT)
+}
+
+// exportDoc is the document JSONEXPORT writes.
+type exportDoc struct {
+	Version int           `json:"version"`
+	Tracks  []exportTrack `json:"tracks"`
+}
+
+// exportTrack is the JSON form of a Track.
+type exportTrack struct {
+	Agent     string       `json:"agent"`
+	Start     jsonPoint    `json:"start"`
+	End       jsonPoint    `json:"end"`
+	Positions []jsonPoint  `json:"positions"`
+	Steps     []exportStep `json:"steps"`
+}
+
+// exportStep is the JSON form of a Step. Start and End are null for a Step that was not timed.
+type exportStep struct {
+	Seq       int        `json:"seq"`
+	Movement  int        `json:"movement"`
+	Start     *time.Time `json:"start"`
+	End       *time.Time `json:"end"`
+	Action    string     `json:"action"`
+	Speed     int        `json:"speed"`
+	Direction string     `json:"direction"`
+	Pace      jsonPoint  `json:"pace"`
+	From      jsonPoint  `json:"from"`
+	To        jsonPoint  `json:"to"`
+}
+
+// ExportJSON writes the tracks to w as one indented JSON document.
+func ExportJSON(w io.Writer, tracks ...Track) error {
+	doc := exportDoc{Version: EXPORTVERSION, Tracks: make([]exportTrack, 0, len(tracks))}
+	for _, t := range tracks {
+		et := exportTrack{
+			Agent:     t.Agent,
+			Start:     *newJSONPoint(t.start()),
+			End:       *newJSONPoint(t.At),
+			Positions: make([]jsonPoint, 0, len(t.Positions)),
+			Steps:     make([]exportStep, 0),
+		}
+		for _, p := range t.Positions {
+			et.Positions = append(et.Positions, *newJSONPoint(p))
+		}
+		for _, st := range t.steps() {
+			es := exportStep{
+				Seq:       st.Seq,
+				Movement:  st.Movement,
+				Action:    st.Action.String(),
+				Speed:     int(st.Speed),
+				Direction: st.Pace.d.String(),
+				Pace:      jsonPoint{X: st.Pace.x, Y: st.Pace.y},
+				From:      *newJSONPoint(st.From),
+				To:        *newJSONPoint(st.To),
+			}
+			if start, end := st.Start, st.End; !start.IsZero() || !end.IsZero() {
+				es.Start, es.End = &start, &end
+			}
+			et.Steps = append(et.Steps, es)
+		}
+		doc.Tracks = append(doc.Tracks, et)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(doc)
+}
+
+// CSVHEADER is the header row ExportCSV writes. time is when the pace ended, in RFC 3339, and empty for a pace that
+// was not timed; x and y are where the pace took the agent.
+var CSVHEADER = []string{"agent", "seq", "time", "x", "y", "direction", "action"}
+
+// ExportCSV writes the tracks to w as CSV: CSVHEADER, then one row per pace of each Track in turn.
+func ExportCSV(w io.Writer, tracks ...Track) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(CSVHEADER); err != nil {
+		return err
+	}
+	for _, t := range tracks {
+		for _, st := range t.steps() {
+			var at string
+			if !st.End.IsZero() {
+				at = st.End.Format(time.RFC3339Nano)
+			}
+			row := []string{
+				t.Agent,
+				strconv.Itoa(st.Seq),
+				at,
+				strconv.Itoa(st.To.X.Int()),
+				strconv.Itoa(st.To.Y.Int()),
+				st.Pace.d.String(),
+				st.Action.String(),
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// geoFeatureCollection, geoFeature and geoGeometry are the GeoJSON objects ExportGeoJSON writes. Coordinates are
+// the X and Y of the plane, not longitudes and latitudes.
+type geoFeatureCollection struct {
+	Type     string       `json:"type"`
+	Features []geoFeature `json:"features"`
+}
+
+type geoFeature struct {
+	Type       string                 `json:"type"`
+	Geometry   geoGeometry            `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+type geoGeometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+// geoPosition returns the GeoJSON position of p.
+func geoPosition(p Point) [2]Coordinate {
+	return [2]Coordinate{p.X, p.Y}
+}
+
+// ExportGeoJSON writes the tracks to w as a GeoJSON FeatureCollection. Each Track gives a LineString feature
+// through every pace it took, when it took any, and a Point feature for where it started and for each of its
+// Positions. Every feature carries the name of its agent and its kind, "path", "start" or "position", in its
+// properties; position features also carry their index among the Positions.
+func ExportGeoJSON(w io.Writer, tracks ...Track) error {
+	fc := geoFeatureCollection{Type: "FeatureCollection", Features: make([]geoFeature, 0)}
+	point := func(p Point, props map[string]interface{}) geoFeature {
+		return geoFeature{Type: "Feature", Geometry: geoGeometry{Type: "Point", Coordinates: geoPosition(p)}, Properties: props}
+	}
+	for _, t := range tracks {
+		if steps := t.steps(); len(steps) > 0 {
+			line := [][2]Coordinate{geoPosition(steps[0].From)}
+			for _, st := range steps {
+				line = append(line, geoPosition(st.To))
+			}
+			fc.Features = append(fc.Features, geoFeature{
+				Type:       "Feature",
+				Geometry:   geoGeometry{Type: "LineString", Coordinates: line},
+				Properties: map[string]interface{}{"agent": t.Agent, "kind": "path"},
+			})
+		}
+		fc.Features = append(fc.Features, point(t.start(), map[string]interface{}{"agent": t.Agent, "kind": "start"}))
+		for i, p := range t.Positions {
+			fc.Features = append(fc.Features, point(p, map[string]interface{}{"agent": t.Agent, "kind": "position", "index": i}))
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(fc)
+}
@@ -0,0 +1,98 @@
+package agent_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+)
+
+func TestExport(t *testing.T) {
+	h, vc := newTestHare(t, 2, 4)
+	vc.Join()
+	defer vc.Leave()
+	if err := h.Walk(2*time.Second, agent.NORTH); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Move(-1, 0); err != nil {
+		t.Fatal(err)
+	}
+	track := h.Track()
+
+	var csv bytes.Buffer
+	if err := agent.Export(&csv, agent.CSVEXPORT, track); err != nil {
+		t.Fatal(err)
+	}
+	want := "agent,seq,time,x,y,direction,action\n" +
+		"agent,0,1970-01-01T00:00:01Z,0,2,NORTH,WALK\n" +
+		"agent,1,1970-01-01T00:00:02Z,0,4,NORTH,WALK\n" +
+		"agent,2,1970-01-01T00:00:02Z,-1,4,LEFT,MOVE\n"
+	if got := csv.String(); got != want {
+		t.Errorf("CSV export =\n%s\nwant\n%s", got, want)
+	}
+
+	var js bytes.Buffer
+	if err := agent.Export(&js, agent.JSONEXPORT, track); err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Version int
+		Tracks  []struct {
+			Agent     string
+			Start     struct{ X, Y int }
+			End       struct{ X, Y int }
+			Positions []struct{ X, Y int }
+			Steps     []struct {
+				Seq       int
+				Start     *time.Time
+				Action    string
+				Direction string
+				To        struct{ X, Y int }
+			}
+		}
+	}
+	if err := json.Unmarshal(js.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Version != agent.EXPORTVERSION || len(doc.Tracks) != 1 {
+		t.Fatalf("JSON export = %s", js.String())
+	}
+	tr := doc.Tracks[0]
+	if tr.Agent != agent.AGENT || tr.End.X != -1 || tr.End.Y != 4 || len(tr.Positions) != 3 || len(tr.Steps) != 3 {
+		t.Errorf("JSON export track = %+v", tr)
+	}
+	if st := tr.Steps[1]; st.Start == nil || !st.Start.Equal(time.Unix(1, 0)) || st.Action != "WALK" || st.To.Y != 4 {
+		t.Errorf("JSON export step 1 = %+v", st)
+	}
+
+	var geo bytes.Buffer
+	if err := agent.Export(&geo, agent.GEOJSONEXPORT, track); err != nil {
+		t.Fatal(err)
+	}
+	var fc struct {
+		Type     string
+		Features []struct {
+			Geometry struct {
+				Type        string
+				Coordinates json.RawMessage
+			}
+			Properties map[string]interface{}
+		}
+	}
+	if err := json.Unmarshal(geo.Bytes(), &fc); err != nil {
+		t.Fatal(err)
+	}
+	if fc.Type != "FeatureCollection" || len(fc.Features) != 5 {
+		t.Fatalf("GeoJSON export = %s", geo.String())
+	}
+	if line := fc.Features[0]; line.Geometry.Type != "LineString" || string(bytes.Join(bytes.Fields(line.Geometry.Coordinates), nil)) != "[[0,0],[0,2],[0,4],[-1,4]]" {
+		t.Errorf("GeoJSON path = %s %s", line.Geometry.Type, line.Geometry.Coordinates)
+	}
+
+	if err := agent.Export(&bytes.Buffer{}, "xml", track); !errors.Is(err, agent.ErrUnknownFormat) {
+		t.Errorf("Export(xml) = %v, want %v", err, agent.ErrUnknownFormat)
+	}
+}
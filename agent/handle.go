@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"sync"
+)
+
+// Handle tracks a movement queued on a Hare by one of its Async methods. It is safe for concurrent use.
+type Handle struct {
+	hare      *Hare
+	id        int
+	action    MovType
+	total     int
+	completed int
+	positions []Point
+	path      *Path
+	err       error
+	done      chan struct{}
+	m         sync.Mutex
+}
+
+// job is a movement waiting in the queue of a Hare.
+type job struct {
+	hd  *Handle
+	run func(hd *Handle) error
+}
+
+func newHandle(h *Hare, action MovType, total int) *Handle {
+	return &Handle{
+		hare:   h,
+		action: action,
+		total:  total,
+		path:   &Path{},
+		done:   make(chan struct{}),
+	}
+}
+
+// ID returns the number of the movement among those queued on the Hare, from 1. The Steps it records carry it as
+// their Movement.
+func (hd *Handle) ID() int {
+	return hd.id
+}
+
+// Action returns the type of the movement.
+func (hd *Handle) Action() MovType {
+	return hd.action
+}
+
+// Done returns a channel that is closed once the movement has ended, whether it completed or not.
+func (hd *Handle) Done() <-chan struct{} {
+	return hd.done
+}
+
+// Progress returns how many paces of the movement have been taken, out of how many in total.
+func (hd *Handle) Progress() (completed, total int) {
+	hd.m.Lock()
+	defer hd.m.Unlock()
+	return hd.completed, hd.total
+}
+
+// Position returns the current position of the Hare carrying out the movement.
+func (hd *Handle) Position() Point {
+	return hd.hare.Position()
+}
+
+// Positions returns a copy of the positions the movement has taken the Hare through so far.
+func (hd *Handle) Positions() []Point {
+	hd.m.Lock()
+	defer hd.m.Unlock()
+	return append([]Point(nil), hd.positions...)
+}
+
+// Path returns a copy of the Path the movement has taken so far.
+func (hd *Handle) Path() *Path {
+	hd.m.Lock()
+	defer hd.m.Unlock()
+	return hd.path.Copy()
+}
+
+// Err returns the error the movement ended with. It is nil while the movement is queued or in progress.
+func (hd *Handle) Err() error {
+	hd.m.Lock()
+	defer hd.m.Unlock()
+	return hd.err
+}
+
+// Wait blocks until the movement has ended and returns its error.
+func (hd *Handle) Wait() error {
+	<-hd.done
+	return hd.Err()
+}
+
+// advance records a completed pace of the movement, which left the Hare at pos.
+func (hd *Handle) advance(pos Point, taken ...Step) {
+	hd.m.Lock()
+	defer hd.m.Unlock()
+	hd.completed++
+	hd.positions = append(hd.positions, pos)
+	for i := range taken {
+		hd.path.M = append(hd.path.M, *taken[i].Pace.PMap())
+		hd.path.A = append(hd.path.A, taken[i].Pace)
+		hd.path.S = append(hd.path.S, taken[i])
+	}
+}
+
+// plan sets the number of paces the movement takes, for movements that only know it once they start.
+func (hd *Handle) plan(total int) {
+	hd.m.Lock()
+	defer hd.m.Unlock()
+	hd.total = total
+}
+
+// finish ends the movement with err.
+func (hd *Handle) finish(err error) {
+	hd.m.Lock()
+	hd.err = err
+	hd.m.Unlock()
+	close(hd.done)
+}
+
+// enqueue queues run as the last movement of the Hare and returns its Handle. Movements are carried out one at a
+// time, in the order they were queued, by a worker goroutine that lives for as long as the queue is not empty.
+func (h *Hare) enqueue(action MovType, total int, run func(hd *Handle) error) *Handle {
+	hd := newHandle(h, action, total)
+	h.m.Lock()
+	defer h.m.Unlock()
+	h.queued++
+	hd.id = h.queued
+	h.queue = append(h.queue, &job{hd: hd, run: run})
+	if !h.working {
+		h.working = true
+		go h.work()
+	}
+	return hd
+}
+
+// work carries out the queued movements until the queue is empty.
+func (h *Hare) work() {
+	for {
+		h.m.Lock()
+		if len(h.queue) == 0 {
+			h.working = false
+			h.m.Unlock()
+			return
+		}
+		j := h.queue[0]
+		h.queue = h.queue[1:]
+		h.m.Unlock()
+		h.emit(Event{Type: ACTIONSTARTED, Action: j.hd.action, Position: h.Position()})
+		err := j.run(j.hd)
+		h.emit(Event{Type: ACTIONFINISHED, Action: j.hd.action, Position: h.Position(), Err: err})
+		j.hd.finish(err)
+	}
+}
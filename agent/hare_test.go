@@ -0,0 +1,643 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/internal/ilog"
+)
+
+// newTestHare returns a Hare running on a VirtualClock, along with the clock. opts are applied as by NewHare.
+func newTestHare(t *testing.T, walk, run agent.Speed, opts ...agent.Opts) (*agent.Hare, *agent.VirtualClock) {
+	t.Helper()
+	logger, err := ilog.NewLogger("ERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vc := agent.NewVirtualClock(time.Unix(0, 0))
+	ctx := context.WithValue(context.Background(), ilog.LOGGERCTX, logger)
+	ctx = context.WithValue(ctx, agent.CLOCKCTX, vc)
+	ctx = context.WithValue(ctx, agent.REPORTERCTX, agent.SilentReporter{})
+	h, err := agent.NewHare(ctx, walk, run, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h, vc
+}
+
+func TestHareReadsWhileWalking(t *testing.T) {
+	h, vc := newTestHare(t, 3, 5)
+
+	done := make(chan error)
+	go func() {
+		done <- h.Walk(10*time.Second, agent.NORTH)
+	}()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				pos := h.Position()
+				if pos.X != 0 || pos.Y%3 != 0 {
+					t.Errorf("read position %v mid-pace", pos)
+				}
+				_ = h.Positions()
+				_ = h.Path()
+				_ = h.Name()
+			}
+		}()
+	}
+
+	for i := 1; i <= 10; i++ {
+		vc.BlockUntil(1)
+		vc.Advance(time.Second)
+	}
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	wg.Wait()
+
+	if got, want := h.Position(), (agent.Point{X: 0, Y: 30}); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+	if got := len(h.Path().A); got != 10 {
+		t.Errorf("len(Path().A) = %d, want 10", got)
+	}
+}
+
+func TestHareConcurrentMovements(t *testing.T) {
+	h, vc := newTestHare(t, 2, 4)
+	vc.Join()
+	defer vc.Leave()
+
+	const movers = 8
+	var wg sync.WaitGroup
+	for i := 0; i < movers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			switch i % 4 {
+			case 0:
+				err = h.Walk(3*time.Second, agent.NORTH)
+			case 1:
+				err = h.Run(2*time.Second, agent.WEST)
+			case 2:
+				err = h.Move(1, -1)
+			case 3:
+				err = h.Wait(2 * time.Second)
+			}
+			if err != nil {
+				t.Error(err)
+			}
+			h.Record(&agent.Point{X: 1})
+			h.RecordWithDirection(agent.NewPace(agent.STILL))
+		}(i)
+	}
+	wg.Wait()
+
+	// each kind of movement ran twice: 2*(3*2) north, 2*(2*4) west, 2*(1, -1)
+	if got, want := h.Position(), (agent.Point{X: 18, Y: 10}); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+	// 3 walking paces, 2 running paces, 2 from the move and 2 waiting paces, twice each,
+	// plus 2 recorded by every mover
+	if got, want := len(h.Path().A), 2*(3+2+2+2)+2*movers; got != want {
+		t.Errorf("len(Path().A) = %d, want %d", got, want)
+	}
+	if got, want := len(h.Positions()), 2*(3+2+1+2); got != want {
+		t.Errorf("len(Positions()) = %d, want %d", got, want)
+	}
+}
+
+func TestHareMovementsAreSerialized(t *testing.T) {
+	h, vc := newTestHare(t, 1, 1)
+
+	first := make(chan error)
+	go func() {
+		first <- h.Walk(2*time.Second, agent.NORTH)
+	}()
+	vc.BlockUntil(1)
+
+	second := make(chan error)
+	go func() {
+		second <- h.Move(5, 0)
+	}()
+
+	vc.Advance(time.Second)
+	vc.BlockUntil(1)
+	if got := h.Position(); got.X != 0 {
+		t.Fatalf("Move ran during Walk: position %v", got)
+	}
+	vc.Advance(time.Second)
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	if err := <-second; err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.Position(), (agent.Point{X: 5, Y: 2}); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+}
+
+func TestSimulationConcurrentAgents(t *testing.T) {
+	logger, err := ilog.NewLogger("ERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	vc := agent.NewVirtualClock(time.Unix(0, 0))
+	ctx := context.WithValue(context.Background(), ilog.LOGGERCTX, logger)
+	ctx = context.WithValue(ctx, agent.CLOCKCTX, vc)
+	ctx = context.WithValue(ctx, agent.REPORTERCTX, agent.SilentReporter{})
+
+	sim := agent.NewSimulation(ctx)
+	work := make(map[string]agent.Work)
+	for _, name := range []string{"a", "b", "c"} {
+		h, err := agent.NewHare(ctx, 1, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sim.Register(name, h); err != nil {
+			t.Fatal(err)
+		}
+		work[name] = func(ctx context.Context, a agent.Agent) error {
+			return a.Walk(5*time.Second, agent.SOUTH)
+		}
+	}
+	if err := sim.Run(ctx, work); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := vc.Now(), time.Unix(5, 0); !got.Equal(want) {
+		t.Errorf("clock at %v after the run, want %v", got, want)
+	}
+	for _, ar := range sim.Report().Agents {
+		if want := (agent.Point{Y: -5}); ar.Position != want {
+			t.Errorf("agent %s at %v, want %v", ar.Name, ar.Position, want)
+		}
+	}
+}
+
+func TestHareAsyncHandles(t *testing.T) {
+	h, vc := newTestHare(t, 2, 3)
+	ctx := context.Background()
+
+	walk := h.WalkAsync(ctx, 3*time.Second, agent.NORTH)
+	move := h.MoveAsync(ctx, 4, 0)
+
+	vc.BlockUntil(1)
+	if completed, total := walk.Progress(); completed != 0 || total != 3 {
+		t.Errorf("walk Progress() = %d, %d, want 0, 3", completed, total)
+	}
+	vc.Advance(time.Second)
+	vc.BlockUntil(1)
+	if completed, _ := walk.Progress(); completed != 1 {
+		t.Errorf("walk Progress() = %d after one pace", completed)
+	}
+	if got, want := walk.Position(), (agent.Point{Y: 2}); got != want {
+		t.Errorf("walk Position() = %v, want %v", got, want)
+	}
+	select {
+	case <-move.Done():
+		t.Fatal("move done before the walk ahead of it")
+	default:
+	}
+
+	for i := 0; i < 2; i++ {
+		vc.BlockUntil(1)
+		vc.Advance(time.Second)
+	}
+	if err := move.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if err := walk.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := len(walk.Path().A); got != 3 {
+		t.Errorf("len(walk.Path().A) = %d, want 3", got)
+	}
+	if got, want := h.Position(), (agent.Point{X: 4, Y: 6}); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+}
+
+func TestHareEvents(t *testing.T) {
+	h, vc := newTestHare(t, 2, 3)
+	vc.Join()
+	defer vc.Leave()
+	h.SetName("bugs")
+
+	var got []agent.Event
+	cancel := h.Subscribe(func(e agent.Event) {
+		got = append(got, e)
+	})
+	events, stop := h.Events()
+
+	if err := h.Walk(2*time.Second, agent.NORTH); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Wait(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Move(1, 0); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := h.Walk(time.Second, agent.SOUTH); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		typ    agent.EventType
+		action agent.MovType
+		pos    agent.Point
+	}{
+		{agent.ACTIONSTARTED, agent.WALK, agent.Point{}},
+		{agent.PACETAKEN, agent.WALK, agent.Point{Y: 2}},
+		{agent.POSITIONCHANGED, agent.WALK, agent.Point{Y: 2}},
+		{agent.PACETAKEN, agent.WALK, agent.Point{Y: 4}},
+		{agent.POSITIONCHANGED, agent.WALK, agent.Point{Y: 4}},
+		{agent.ACTIONFINISHED, agent.WALK, agent.Point{Y: 4}},
+		{agent.ACTIONSTARTED, agent.WAIT, agent.Point{Y: 4}},
+		{agent.PACETAKEN, agent.WAIT, agent.Point{Y: 4}},
+		{agent.ACTIONFINISHED, agent.WAIT, agent.Point{Y: 4}},
+		{agent.ACTIONSTARTED, agent.MOVE, agent.Point{Y: 4}},
+		{agent.PACETAKEN, agent.MOVE, agent.Point{X: 1, Y: 4}},
+		{agent.POSITIONCHANGED, agent.MOVE, agent.Point{X: 1, Y: 4}},
+		{agent.ACTIONFINISHED, agent.MOVE, agent.Point{X: 1, Y: 4}},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		e := got[i]
+		if e.Type != w.typ || e.Action != w.action || e.Position != w.pos || e.Agent != "bugs" {
+			t.Errorf("event %d = %v %v at %v by %q, want %v %v at %v", i, e.Type, e.Action, e.Position, e.Agent, w.typ, w.action, w.pos)
+		}
+	}
+	if got, want := got[4].Time, time.Unix(2, 0); !got.Equal(want) {
+		t.Errorf("second pace at %v, want %v", got, want)
+	}
+
+	// the channel saw everything the listener did, and the last walk too
+	var n int
+	for range events {
+		n++
+		if n == len(want)+4 {
+			stop()
+		}
+	}
+	if n != len(want)+4 {
+		t.Errorf("received %d events, want %d", n, len(want)+4)
+	}
+}
+
+func TestUnknownDirectionsReturnErrors(t *testing.T) {
+	h, _ := newTestHare(t, 1, 1)
+	bogus := agent.Direction(-1)
+
+	if got := bogus.String(); got != "Direction(-1)" {
+		t.Errorf("String() = %q", got)
+	}
+	if err := agent.NewPace(bogus).ScalarMove(1); !errors.Is(err, agent.ErrUnknownDirection) {
+		t.Errorf("ScalarMove() = %v, want ErrUnknownDirection", err)
+	}
+	if _, err := agent.NewPace(agent.XDIRECTION).Result(); err != nil {
+		t.Errorf("Result() = %v for XDIRECTION", err)
+	}
+	if _, err := agent.NewPace(bogus).Result(); !errors.Is(err, agent.ErrUnknownDirection) {
+		t.Errorf("Result() = %v, want ErrUnknownDirection", err)
+	}
+	if err := h.Walk(time.Second, bogus); !errors.Is(err, agent.ErrUnknownDirection) {
+		t.Errorf("Walk() = %v, want ErrUnknownDirection", err)
+	}
+	if err := h.RecordWithDirection(agent.NewPace(bogus)); !errors.Is(err, agent.ErrUnknownDirection) {
+		t.Errorf("RecordWithDirection() = %v, want ErrUnknownDirection", err)
+	}
+	if got := len(h.Path().A); got != 0 {
+		t.Errorf("recorded %d paces of an unknown direction", got)
+	}
+	if _, err := agent.NewHare(context.Background(), -1, 2); !errors.Is(err, agent.ErrInvalidSpeed) {
+		t.Errorf("NewHare() = %v, want ErrInvalidSpeed", err)
+	}
+}
+
+func TestHareRecordsTimestampedSteps(t *testing.T) {
+	h, vc := newTestHare(t, 2, 3)
+	vc.Join()
+	defer vc.Leave()
+
+	if err := h.Walk(2*time.Second, agent.NORTH); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Run(1500*time.Millisecond, agent.SOUTHWEST); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Wait(time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Move(-1, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	at := func(ms int64) time.Time { return time.UnixMilli(ms) }
+	want := []struct {
+		start, end time.Time
+		action     agent.MovType
+		speed      agent.Speed
+		from, to   agent.Point
+	}{
+		{at(0), at(1000), agent.WALK, 2, agent.Point{}, agent.Point{Y: 2}},
+		{at(1000), at(2000), agent.WALK, 2, agent.Point{Y: 2}, agent.Point{Y: 4}},
+		{at(2000), at(3000), agent.RUN, 3, agent.Point{Y: 4}, agent.Point{X: 3, Y: 1}},
+		{at(3000), at(3500), agent.RUN, 3, agent.Point{X: 3, Y: 1}, agent.Point{X: 6, Y: -2}},
+		{at(3500), at(4500), agent.WAIT, 0, agent.Point{X: 6, Y: -2}, agent.Point{X: 6, Y: -2}},
+		{at(4500), at(4500), agent.MOVE, 0, agent.Point{X: 6, Y: -2}, agent.Point{X: 5, Y: -2}},
+		{at(4500), at(4500), agent.MOVE, 0, agent.Point{X: 5, Y: -2}, agent.Point{X: 5, Y: 0}},
+	}
+	path := h.Path()
+	if len(path.S) != len(want) || len(path.A) != len(want) {
+		t.Fatalf("recorded %d steps and %d paces, want %d", len(path.S), len(path.A), len(want))
+	}
+	for i, w := range want {
+		st := path.S[i]
+		if st.Seq != i || !st.Start.Equal(w.start) || !st.End.Equal(w.end) || st.Action != w.action ||
+			st.Speed != w.speed || st.From != w.from || st.To != w.to || st.Pace != path.A[i] {
+			t.Errorf("step %d = %+v, want %+v", i, st, w)
+		}
+	}
+	if got := path.S[3].Duration(); got != 500*time.Millisecond {
+		t.Errorf("last running step took %v, want 500ms", got)
+	}
+}
+
+func TestHarePositionAndSegmentAt(t *testing.T) {
+	h, vc := newTestHare(t, 2, 4)
+	vc.Join()
+	defer vc.Leave()
+
+	at := func(ms int64) time.Time { return time.UnixMilli(ms) }
+	if got, want := h.PositionAt(at(500)), (agent.Vector{}); got != want {
+		t.Errorf("PositionAt() = %v before moving, want %v", got, want)
+	}
+	walk := h.WalkAsync(context.Background(), 2*time.Second, agent.NORTH)
+	run := h.RunAsync(context.Background(), time.Second, agent.SOUTHWEST)
+	if err := run.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	vc.Advance(2 * time.Second) // idle
+	if err := h.Move(-4, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		ms   int64
+		want agent.Vector
+	}{
+		{-1000, agent.Vector{}},
+		{0, agent.Vector{}},
+		{500, agent.Vector{Y: 1}},
+		{1250, agent.Vector{Y: 2.5}},
+		{2000, agent.Vector{Y: 4}},
+		{2250, agent.Vector{X: 1, Y: 3}},
+		{4000, agent.Vector{X: 4, Y: 0}},
+		{5000, agent.Vector{X: 0, Y: 0}},
+		{9000, agent.Vector{X: 0, Y: 0}},
+	} {
+		if got := h.PositionAt(at(c.ms)); got != c.want {
+			t.Errorf("PositionAt(%dms) = %v, want %v", c.ms, got, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		ms       int64
+		movement int
+		action   agent.MovType
+		ok       bool
+	}{
+		{-1, 0, 0, false},
+		{0, walk.ID(), agent.WALK, true},
+		{1999, walk.ID(), agent.WALK, true},
+		{2000, run.ID(), agent.RUN, true},
+		{3500, 0, 0, false},
+		{5000, run.ID() + 1, agent.MOVE, true},
+	} {
+		seg, ok := h.SegmentAt(at(c.ms))
+		if ok != c.ok || seg.Movement != c.movement || (ok && seg.Action != c.action) {
+			t.Errorf("SegmentAt(%dms) = %v %v, %v, want %v %v, %v", c.ms, seg.Movement, seg.Action, ok, c.movement, c.action, c.ok)
+		}
+	}
+	if segs := h.Path().Segments(); len(segs) != 3 || len(segs[0].Steps) != 2 || segs[0].To != (agent.Point{Y: 4}) {
+		t.Errorf("Segments() = %+v", segs)
+	}
+}
+
+func TestHareStats(t *testing.T) {
+	h, vc := newTestHare(t, 2, 4)
+	vc.Join()
+	defer vc.Leave()
+
+	h.WalkAsync(context.Background(), 2*time.Second, agent.NORTH)
+	if err := h.RunAsync(context.Background(), time.Second, agent.SOUTHWEST).Wait(); err != nil {
+		t.Fatal(err)
+	}
+	vc.Advance(2 * time.Second) // idle
+	if err := h.Move(-4, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	st := h.Stats()
+	diag := math.Sqrt(32)
+	if st.Paces != 4 || math.Abs(st.Distance-(8+diag)) > 1e-9 || st.NetDistance != 0 || st.Bearing != 0 {
+		t.Errorf("Stats() paces %d, distance %v, net %v, bearing %v, want 4, %v, 0, 0", st.Paces, st.Distance, st.NetDistance, st.Bearing, 8+diag)
+	}
+	if want := (agent.Bounds{Max: agent.Point{X: 4, Y: 4}}); st.Bounds != want {
+		t.Errorf("Stats().Bounds = %v, want %v", st.Bounds, want)
+	}
+	if st.Elapsed != 5*time.Second || st.TimeByAction[agent.WALK] != 2*time.Second || st.TimeByAction[agent.RUN] != time.Second {
+		t.Errorf("Stats() elapsed %v, by action %v", st.Elapsed, st.TimeByAction)
+	}
+	if math.Abs(st.AverageSpeed-(8+diag)/5) > 1e-9 || math.Abs(st.MaxSpeed-diag) > 1e-9 {
+		t.Errorf("Stats() speeds %v average, %v max", st.AverageSpeed, st.MaxSpeed)
+	}
+	want := []agent.Point{{Y: 4}, {X: 4}}
+	if st.DirectionChanges != 2 || len(st.TurningPoints) != 2 || st.TurningPoints[0] != want[0] || st.TurningPoints[1] != want[1] {
+		t.Errorf("Stats() %d direction changes at %v, want 2 at %v", st.DirectionChanges, st.TurningPoints, want)
+	}
+
+	p := agent.Point{X: 3, Y: -4}
+	if st := p.Path().Stats(); st.Distance != 7 || st.NetDistance != 5 || math.Round(st.Bearing) != 217 {
+		t.Errorf("Point.Path().Stats() distance %v, net %v, bearing %v", st.Distance, st.NetDistance, st.Bearing)
+	}
+}
+
+func TestHareMoveTo(t *testing.T) {
+	legs := agent.PlanLegs(agent.Point{X: 1, Y: 1}, agent.Point{X: 10, Y: -4})
+	if len(legs) != 2 || legs[0].Direction != agent.SOUTHWEST || legs[0].Distance != 5 || legs[1].Direction != agent.WEST || legs[1].Distance != 4 {
+		t.Fatalf("PlanLegs() = %+v", legs)
+	}
+
+	h, vc := newTestHare(t, 3, 5, agent.WithGait(agent.RunBeyond(4)))
+	vc.Join()
+	defer vc.Leave()
+	hd := h.MoveToAsync(context.Background(), agent.Point{X: 9, Y: -5})
+	if err := hd.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	if completed, total := hd.Progress(); completed != 3 || total != 3 {
+		t.Errorf("Progress() = %d, %d, want 3, 3", completed, total)
+	}
+	// SOUTHWEST by 5, run in one pace, then WEST by 4, walked in a pace of 3 and one of 1 lasting a third of a second
+	steps := h.Path().S
+	want := []struct {
+		action agent.MovType
+		to     agent.Point
+		took   time.Duration
+	}{
+		{agent.RUN, agent.Point{X: 5, Y: -5}, time.Second},
+		{agent.WALK, agent.Point{X: 8, Y: -5}, time.Second},
+		{agent.WALK, agent.Point{X: 9, Y: -5}, time.Second / 3},
+	}
+	if len(steps) != len(want) {
+		t.Fatalf("MoveTo recorded %d steps, want %d", len(steps), len(want))
+	}
+	for i, w := range want {
+		if st := steps[i]; st.Action != w.action || st.To != w.to || st.Duration() != w.took || st.Movement != hd.ID() {
+			t.Errorf("step %d = %+v, want %v to %v in %v", i, st, w.action, w.to, w.took)
+		}
+	}
+
+	if err := h.FollowWaypoints([]agent.Point{{X: 9, Y: 0}, {}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Position(); got != (agent.Point{}) {
+		t.Errorf("Position() = %v after FollowWaypoints, want the origin", got)
+	}
+
+	still, vc2 := newTestHare(t, 0, 0)
+	vc2.Join()
+	defer vc2.Leave()
+	if err := still.MoveTo(agent.Point{X: 1}); !errors.Is(err, agent.ErrInvalidSpeed) {
+		t.Errorf("MoveTo() at speed 0 = %v, want %v", err, agent.ErrInvalidSpeed)
+	}
+}
+
+func TestHareCancelledMidMovement(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		move func(h *agent.Hare, ctx context.Context) error
+		at   agent.Point
+	}{
+		{"walk", func(h *agent.Hare, ctx context.Context) error { return h.WalkContext(ctx, 10*time.Second, agent.NORTH) }, agent.Point{Y: 6}},
+		{"run", func(h *agent.Hare, ctx context.Context) error { return h.RunContext(ctx, 10*time.Second, agent.WEST) }, agent.Point{X: 12}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			h, vc := newTestHare(t, 2, 4)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			done := make(chan error)
+			go func() {
+				done <- tc.move(h, ctx)
+			}()
+			for i := 0; i < 3; i++ {
+				vc.BlockUntil(1)
+				vc.Advance(time.Second)
+			}
+			// the fourth pace is under way when the context is cancelled
+			vc.BlockUntil(1)
+			cancel()
+			err := <-done
+
+			if !errors.Is(err, context.Canceled) {
+				t.Fatalf("%s = %v, want context.Canceled", tc.name, err)
+			}
+			var pme *agent.PartialMoveError
+			if !errors.As(err, &pme) {
+				t.Fatalf("%s = %v, want a PartialMoveError", tc.name, err)
+			}
+			if pme.Completed != 3 || pme.Total != 10 || pme.From != (agent.Point{}) || pme.At != tc.at {
+				t.Errorf("PartialMoveError = %+v, want 3 of 10 paces from the origin to %v", pme, tc.at)
+			}
+			vc.Advance(10 * time.Second)
+			if got := h.Position(); got != tc.at {
+				t.Errorf("Position() = %v after the cancel, want %v", got, tc.at)
+			}
+			if got := len(h.Path().S); got != 3 {
+				t.Errorf("recorded %d steps, want 3", got)
+			}
+		})
+	}
+}
+
+func TestHareWalksDiagonally(t *testing.T) {
+	h, vc := newTestHare(t, 2, 5)
+	vc.Join()
+	defer vc.Leave()
+
+	// WEST is +X and NORTH +Y: a diagonal pace covers the speed along both axes
+	for _, tc := range []struct {
+		move func() error
+		at   agent.Point
+	}{
+		{func() error { return h.Walk(3*time.Second, agent.NORTHWEST) }, agent.Point{X: 6, Y: 6}},
+		{func() error { return h.Walk(2*time.Second, agent.SOUTHEAST) }, agent.Point{X: 2, Y: 2}},
+		{func() error { return h.Run(time.Second, agent.SOUTHWEST) }, agent.Point{X: 7, Y: -3}},
+		{func() error { return h.Run(time.Second, agent.NORTHEAST) }, agent.Point{X: 2, Y: 2}},
+	} {
+		if err := tc.move(); err != nil {
+			t.Fatal(err)
+		}
+		if got := h.Position(); got != tc.at {
+			t.Errorf("Position() = %v, want %v", got, tc.at)
+		}
+	}
+	if got := vc.Now().Sub(time.Unix(0, 0)); got != 7*time.Second {
+		t.Errorf("the moves took %v, want 7s", got)
+	}
+}
+
+func TestDiagonalPaceMapRoundTrip(t *testing.T) {
+	for _, tc := range []struct {
+		d  agent.Direction
+		at agent.Point
+	}{
+		{agent.NORTHEAST, agent.Point{X: -3, Y: 3}},
+		{agent.NORTHWEST, agent.Point{X: 3, Y: 3}},
+		{agent.SOUTHEAST, agent.Point{X: -3, Y: -3}},
+		{agent.SOUTHWEST, agent.Point{X: 3, Y: -3}},
+	} {
+		pace := agent.NewPace(tc.d)
+		if err := pace.ScalarMove(3); err != nil {
+			t.Fatal(err)
+		}
+		if got := *pace.Point(); got != tc.at {
+			t.Errorf("%v pace of 3 = %v, want %v", tc.d, got, tc.at)
+		}
+		pm := pace.PMap()
+		if len(*pm) != 2 {
+			t.Errorf("PMap() of a %v pace = %v, want both axes", tc.d, *pm)
+		}
+		back := pm.Pace()
+		if got := *back.Point(); got != tc.at {
+			t.Errorf("PMap().Pace() of a %v pace = %v, want %v", tc.d, got, tc.at)
+		}
+		if got := back.PMap(); !reflect.DeepEqual(*got, *pm) {
+			t.Errorf("PMap() after the round trip = %v, want %v", *got, *pm)
+		}
+	}
+}
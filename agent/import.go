@@ -0,0 +1,221 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// GPXIMPORT reads tracks from GPX. Along with JSONEXPORT and CSVEXPORT, it is one of the formats Import reads.
+const GPXIMPORT = "gpx"
+
+// EARTHRADIUS is the radius of the Earth in metres, which GPX tracks are projected onto the plane with.
+const EARTHRADIUS = 6371000.0
+
+// Import reads the Recordings in r, written in format: JSONEXPORT, CSVEXPORT or GPXIMPORT. Any other format yields
+// an error wrapping ErrUnknownFormat; contents that cannot be read yield one wrapping ErrInvalidRecording.
+func Import(r io.Reader, format string) ([]Recording, error) {
+	switch format {
+	case JSONEXPORT:
+		return ImportJSON(r)
+	case CSVEXPORT:
+		return ImportCSV(r)
+	case GPXIMPORT:
+		return ImportGPX(r)
+	}
+	return nil, fmt.Errorf("%w: %q, use %q, %q or %q", ErrUnknownFormat, format, JSONEXPORT, CSVEXPORT, GPXIMPORT)
+}
+
+// parseTimestamp reads a timestamp in RFC 3339, or as a number of seconds since the Unix epoch.
+func parseTimestamp(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	sec, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("timestamp %q is neither RFC 3339 nor seconds", s)
+	}
+	whole, frac := math.Modf(sec)
+	return time.Unix(int64(whole), int64(math.Round(frac*1e9))).UTC(), nil
+}
+
+// importSample is the JSON form of a Sample. Time is a string in RFC 3339 or a number of seconds.
+type importSample struct {
+	Time json.RawMessage `json:"time"`
+	X    Coordinate      `json:"x"`
+	Y    Coordinate      `json:"y"`
+}
+
+// ImportJSON reads either a document written by ExportJSON, giving one Recording per track, or a bare array of
+// samples such as [{"time": "2024-01-01T00:00:00Z", "x": 0, "y": 0}], giving one Recording without a name. A track
+// exported by ExportJSON starts where its first Step does, when that Step started; each Step then adds a Sample
+// where and when it ended.
+func ImportJSON(r io.Reader) ([]Recording, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var samples []importSample
+		if err := json.Unmarshal(data, &samples); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRecording, err)
+		}
+		rec := Recording{}
+		for i, s := range samples {
+			t, err := parseTimestamp(strings.Trim(string(s.Time), `"`))
+			if err != nil {
+				return nil, fmt.Errorf("%w: sample %d: %w", ErrInvalidRecording, i, err)
+			}
+			rec.Samples = append(rec.Samples, Sample{Time: t, At: Point{X: s.X, Y: s.Y}})
+		}
+		return []Recording{rec}, nil
+	}
+
+	var doc exportDoc
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRecording, err)
+	}
+	if doc.Version != EXPORTVERSION {
+		return nil, fmt.Errorf("%w: export version %d, want %d", ErrInvalidRecording, doc.Version, EXPORTVERSION)
+	}
+	recs := make([]Recording, 0, len(doc.Tracks))
+	for _, tr := range doc.Tracks {
+		rec := Recording{Agent: tr.Agent}
+		for i, st := range tr.Steps {
+			if st.Start == nil || st.End == nil {
+				return nil, fmt.Errorf("%w: track %s: step %d was not timed", ErrInvalidRecording, tr.Agent, i)
+			}
+			if i == 0 {
+				rec.Samples = append(rec.Samples, Sample{Time: *st.Start, At: Point{X: st.From.X, Y: st.From.Y}})
+			}
+			rec.Samples = append(rec.Samples, Sample{Time: *st.End, At: Point{X: st.To.X, Y: st.To.Y}})
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
+
+// ImportCSV reads CSV with a header row naming a timestamp (or time), an x and a y column, in any order. An agent
+// column, as written by ExportCSV, splits the rows into one Recording per agent, in the order they first appear;
+// without one, every row belongs to a single Recording without a name. Other columns are ignored.
+func ImportCSV(r io.Reader) ([]Recording, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading header: %w", ErrInvalidRecording, err)
+	}
+	col := map[string]int{}
+	for i, name := range header {
+		col[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	tcol, ok := col["timestamp"]
+	if !ok {
+		tcol, ok = col["time"]
+	}
+	xcol, xok := col["x"]
+	ycol, yok := col["y"]
+	if !ok || !xok || !yok {
+		return nil, fmt.Errorf("%w: header %v needs timestamp, x and y columns", ErrInvalidRecording, header)
+	}
+	acol, named := col["agent"]
+
+	var recs []Recording
+	index := map[string]int{}
+	for line := 2; ; line++ {
+		row, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidRecording, err)
+		}
+		field := func(i int) string {
+			if i < len(row) {
+				return row[i]
+			}
+			return ""
+		}
+		t, err := parseTimestamp(field(tcol))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidRecording, line, err)
+		}
+		x, errx := strconv.Atoi(strings.TrimSpace(field(xcol)))
+		y, erry := strconv.Atoi(strings.TrimSpace(field(ycol)))
+		if err := errors.Join(errx, erry); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidRecording, line, err)
+		}
+		var name string
+		if named {
+			name = field(acol)
+		}
+		i, ok := index[name]
+		if !ok {
+			i = len(recs)
+			index[name] = i
+			recs = append(recs, Recording{Agent: name})
+		}
+		recs[i].Samples = append(recs[i].Samples, Sample{Time: t, At: Point{X: Coordinate(x), Y: Coordinate(y)}})
+	}
+	return recs, nil
+}
+
+// gpxDoc is the part of a GPX document ImportGPX reads.
+type gpxDoc struct {
+	Tracks []struct {
+		Name     string `xml:"name"`
+		Segments []struct {
+			Points []struct {
+				Lat  float64 `xml:"lat,attr"`
+				Lon  float64 `xml:"lon,attr"`
+				Time string  `xml:"time"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// ImportGPX reads the tracks of a GPX document, one Recording per track, joining its segments. Track points are
+// projected onto the plane one Coordinate per metre, around the first point of the track, which is the origin:
+// north along +Y and east along -X, the way agents move. Every track point must carry a time.
+func ImportGPX(r io.Reader) ([]Recording, error) {
+	var doc gpxDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRecording, err)
+	}
+	recs := make([]Recording, 0, len(doc.Tracks))
+	for _, trk := range doc.Tracks {
+		rec := Recording{Agent: trk.Name}
+		var lat0, lon0 float64
+		for _, seg := range trk.Segments {
+			for _, pt := range seg.Points {
+				if len(rec.Samples) == 0 {
+					lat0, lon0 = pt.Lat, pt.Lon
+				}
+				if pt.Time == "" {
+					return nil, fmt.Errorf("%w: track %q: point %d has no time", ErrInvalidRecording, trk.Name, len(rec.Samples))
+				}
+				t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(pt.Time))
+				if err != nil {
+					return nil, fmt.Errorf("%w: track %q: point %d: %w", ErrInvalidRecording, trk.Name, len(rec.Samples), err)
+				}
+				rad := math.Pi / 180
+				east := EARTHRADIUS * (pt.Lon - lon0) * rad * math.Cos(lat0*rad)
+				north := EARTHRADIUS * (pt.Lat - lat0) * rad
+				at := Point{X: Coordinate(math.Round(-east)), Y: Coordinate(math.Round(north))}
+				rec.Samples = append(rec.Samples, Sample{Time: t, At: at})
+			}
+		}
+		recs = append(recs, rec)
+	}
+	return recs, nil
+}
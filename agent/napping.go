@@ -0,0 +1,78 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// NappingHare is a Hare that, now and then, takes a nap before it runs. Naps are recorded in the Path like any
+// other Wait.
+type NappingHare struct {
+	*Hare
+	odds float64       // odds is the chance, between 0 and 1, of napping before a run
+	nap  time.Duration // nap is how long each nap lasts
+	rand *rand.Rand
+	rm   sync.Mutex
+}
+
+// NewNappingHare returns a NappingHare that naps for nap, which must be positive, before a run with the given odds.
+// Naps are drawn from a source seeded with seed, so the same seed gives the same naps. opts are applied as by NewHare.
+func NewNappingHare(ctx context.Context, walk, run Speed, odds float64, nap time.Duration, seed int64, opts ...Opts) (*NappingHare, error) {
+	if odds < 0 || odds > 1 {
+		return nil, fmt.Errorf("nap odds %v are not between 0 and 1", odds)
+	}
+	if nap <= 0 {
+		return nil, fmt.Errorf("nap %v is not positive", nap)
+	}
+	h, err := NewHare(ctx, walk, run, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &NappingHare{
+		Hare: h,
+		odds: odds,
+		nap:  nap,
+		rand: rand.New(rand.NewSource(seed)),
+	}, nil
+}
+
+// drowsy reports whether the NappingHare naps before its next run.
+func (n *NappingHare) drowsy() bool {
+	n.rm.Lock()
+	defer n.rm.Unlock()
+	return n.rand.Float64() < n.odds
+}
+
+// Run maybe naps, then runs.
+func (n *NappingHare) Run(duration time.Duration, dir Direction) error {
+	return n.RunContext(n.ctx, duration, dir)
+}
+
+// RunContext maybe naps, then runs.
+func (n *NappingHare) RunContext(ctx context.Context, duration time.Duration, dir Direction) error {
+	if n.drowsy() {
+		if err := n.WaitContext(ctx, n.nap); err != nil {
+			return err
+		}
+	}
+	return n.Hare.RunContext(ctx, duration, dir)
+}
+
+// Stride either naps or runs for one second.
+func (n *NappingHare) Stride(ctx context.Context, dir Direction) error {
+	if n.drowsy() {
+		return n.WaitContext(ctx, n.nap)
+	}
+	return n.Hare.RunContext(ctx, time.Second, dir)
+}
+
+// RunAsync maybe queues a nap, then queues the run and returns its Handle.
+func (n *NappingHare) RunAsync(ctx context.Context, duration time.Duration, dir Direction) *Handle {
+	if n.drowsy() {
+		n.WaitAsync(ctx, n.nap)
+	}
+	return n.Hare.RunAsync(ctx, duration, dir)
+}
@@ -0,0 +1,41 @@
+package agent_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+)
+
+func TestNewNappingHareRejectsNaps(t *testing.T) {
+	ctx := raceContext(t)
+	for _, nap := range []time.Duration{0, -time.Second} {
+		if _, err := agent.NewNappingHare(ctx, 2, 5, 0.5, nap, 1); err == nil {
+			t.Errorf("NewNappingHare() with a nap of %v succeeded, want an error", nap)
+		}
+	}
+	if _, err := agent.NewNappingHare(ctx, 2, 5, 1.5, time.Second, 1); err == nil {
+		t.Error("NewNappingHare() with odds of 1.5 succeeded, want an error")
+	}
+}
+
+func TestNappingHareNapsBeforeRunning(t *testing.T) {
+	ctx := raceContext(t)
+	n, err := agent.NewNappingHare(ctx, 2, 5, 1, 3*time.Second, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := agent.NewSimulation(ctx)
+	if err := sim.Register("hare", n); err != nil {
+		t.Fatal(err)
+	}
+	race := &agent.Race{Finish: 10, Direction: agent.NORTH, Limit: 10 * time.Second}
+	results, err := race.Run(ctx, sim)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// with odds of 1 every stride is a nap: the hare never leaves the start
+	if r := results[0]; r.Finished || r.Distance != 0 || r.Time != 12*time.Second {
+		t.Errorf("result = %+v, want an unfinished race of 12s at distance 0", r)
+	}
+}
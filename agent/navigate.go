@@ -0,0 +1,206 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"github.com/dark-enstein/chardot/internal/ilog"
+	"time"
+)
+
+// Leg is a straight part of a route: Distance units toward Direction, along each of its axes for an intercardinal
+// one, from From to To.
+type Leg struct {
+	Direction Direction
+	Distance  Coordinate
+	From, To  Point
+}
+
+// PlanLegs returns the legs that take an agent from from to to: a diagonal leg for as long as both axes need
+// covering, then a leg along the axis left. It returns no legs when from is to.
+func PlanLegs(from, to Point) []Leg {
+	x, y := to.X-from.X, to.Y-from.Y
+	diag := x.abs()
+	if y.abs() < diag {
+		diag = y.abs()
+	}
+	var legs []Leg
+	at := Point{X: from.X, Y: from.Y}
+	for _, shift := range []struct{ x, y Coordinate }{
+		{sign(x) * diag, sign(y) * diag},
+		{x - sign(x)*diag, y - sign(y)*diag},
+	} {
+		d, ok := headingOf(shift.x, shift.y)
+		if !ok {
+			continue
+		}
+		dist := shift.x.abs()
+		if dist == 0 {
+			dist = shift.y.abs()
+		}
+		next := Point{X: at.X + shift.x, Y: at.Y + shift.y}
+		legs = append(legs, Leg{Direction: d, Distance: dist, From: at, To: next})
+		at = next
+	}
+	return legs
+}
+
+// sign returns -1, 0 or 1 as c is negative, zero or positive.
+func sign(c Coordinate) Coordinate {
+	switch {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	}
+	return 0
+}
+
+// Gait picks whether a Hare walks or runs a Leg: WALK or RUN.
+type Gait func(leg Leg) MovType
+
+var (
+	WALKGAIT Gait = func(Leg) MovType { return WALK } // WALKGAIT walks every Leg. It is the default Gait of a Hare.
+	RUNGAIT  Gait = func(Leg) MovType { return RUN }  // RUNGAIT runs every Leg.
+)
+
+// RunBeyond returns a Gait that runs the legs longer than n and walks the others.
+func RunBeyond(n Coordinate) Gait {
+	return func(leg Leg) MovType {
+		if leg.Distance > n {
+			return RUN
+		}
+		return WALK
+	}
+}
+
+// Navigator is implemented by agents that can go to a point of their own accord, such as Hare.
+type Navigator interface {
+	MoveToContext(ctx context.Context, p Point) error
+	FollowWaypointsContext(ctx context.Context, waypoints []Point) error
+}
+
+// MoveTo takes the Hare to p, over the legs PlanLegs plans, or the legs of the shortest Route around the obstacles
+// when the Hare is in a World, walking or running each as its Gait picks. Unlike Move, it takes time: paces cover
+// the walking or running Speed of the Hare each second, the last pace of a leg only what is left of it, in
+// proportion of a second. The legs are recorded in the Path as WALK and RUN Steps of a single GOTO movement. A leg
+// the Hare would take at a Speed of 0 cannot be taken, and yields an error wrapping ErrInvalidSpeed; a point no
+// route leads to yields one wrapping ErrNoRoute, or ErrBlocked if it is not free, before the Hare moves at all.
+func (h *Hare) MoveTo(p Point) error {
+	return h.MoveToContext(h.ctx, p)
+}
+
+// MoveToContext is MoveTo bound to ctx instead of the Hare's own context. If ctx is cancelled or its deadline
+// passes, the Hare stops at the last completed pace and a *PartialMoveError is returned.
+func (h *Hare) MoveToContext(ctx context.Context, p Point) error {
+	return h.MoveToAsync(ctx, p).Wait()
+}
+
+// MoveToAsync queues a MoveTo bound to ctx and returns its Handle at once. The Handle learns how many paces the
+// movement takes once it starts.
+func (h *Hare) MoveToAsync(ctx context.Context, p Point) *Handle {
+	return h.FollowWaypointsAsync(ctx, []Point{p})
+}
+
+// FollowWaypoints takes the Hare to each of waypoints in turn, as MoveTo does, in a single GOTO movement.
+func (h *Hare) FollowWaypoints(waypoints []Point) error {
+	return h.FollowWaypointsContext(h.ctx, waypoints)
+}
+
+// FollowWaypointsContext is FollowWaypoints bound to ctx instead of the Hare's own context.
+func (h *Hare) FollowWaypointsContext(ctx context.Context, waypoints []Point) error {
+	return h.FollowWaypointsAsync(ctx, waypoints).Wait()
+}
+
+// FollowWaypointsAsync queues a FollowWaypoints bound to ctx and returns its Handle at once.
+func (h *Hare) FollowWaypointsAsync(ctx context.Context, waypoints []Point) *Handle {
+	waypoints = append([]Point(nil), waypoints...)
+	return h.enqueue(GOTO, 0, func(hd *Handle) error {
+		former := h.begin(GOTO)
+		posStack, dist, err := h.navigate(ctx, waypoints, hd)
+		h.report(Report{Kind: REPORTEND, Action: GOTO, From: former, At: h.Position(), Path: dist, Positions: posStack, Err: err})
+		return err
+	})
+}
+
+// leg is a Leg as the Hare takes it: at which Speed, and in how many paces.
+type leg struct {
+	Leg
+	action MovType
+	speed  Speed
+	paces  int
+}
+
+// navigate takes the Hare through waypoints, the way flow moves it in one Direction. It is only run by the queue
+// worker.
+func (h *Hare) navigate(ctx context.Context, waypoints []Point, hd *Handle) ([]Point, *Path, error) {
+	h.m.Lock()
+	from, gait, world := h.pos, h.gait, h.world
+	walk, run := h.nature.walk, h.nature.run
+	h.m.Unlock()
+
+	var legs []leg
+	total, at := 0, from
+	for _, wp := range waypoints {
+		planned := PlanLegs(at, wp)
+		if world != nil {
+			var err error
+			if planned, err = world.PlanLegs(at, wp); err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, l := range planned {
+			lg := leg{Leg: l, action: gait(l), speed: walk}
+			if lg.action == RUN {
+				lg.speed = run
+			}
+			if lg.speed <= 0 {
+				return nil, nil, fmt.Errorf("%w: cannot %v %d toward %v at %d", ErrInvalidSpeed, lg.action, l.Distance, l.Direction, lg.speed)
+			}
+			lg.paces = int((l.Distance + lg.speed.Int() - 1) / lg.speed.Int())
+			total += lg.paces
+			legs = append(legs, lg)
+		}
+		at = Point{X: wp.X, Y: wp.Y}
+	}
+	hd.plan(total)
+
+	endPosition := make([]Point, 0, total)
+	pathTaken := &Path{}
+	h.report(Report{Kind: REPORTSTART, Action: GOTO, From: from})
+	for _, lg := range legs {
+		h.begin(lg.action)
+		left := lg.Distance
+		for i := 0; i < lg.paces; i++ {
+			step, tick := lg.speed.Int(), time.Second
+			if left < step {
+				step, tick = left, time.Duration(int64(time.Second)*int64(left)/int64(lg.speed))
+			}
+			pace := NewPace(lg.Direction)
+			err := pace.ScalarMove(step)
+			var st Step
+			if err == nil {
+				st, err = h.takePace(ctx, pace, lg.speed, tick, hd)
+			}
+			if err != nil {
+				h.logger.Log(ilog.ERROR, "Travel ended prematurely. Only completed %d out of %d paces.", len(endPosition), total)
+				h.begin(GOTO)
+				return endPosition, pathTaken, &PartialMoveError{
+					Action:    GOTO,
+					Direction: lg.Direction,
+					Completed: len(endPosition),
+					Total:     total,
+					From:      from,
+					At:        h.Position(),
+					Err:       err,
+				}
+			}
+			left -= step
+			pathTaken.M = append(pathTaken.M, *pace.PMap())
+			pathTaken.A = append(pathTaken.A, *pace)
+			pathTaken.S = append(pathTaken.S, st)
+			endPosition = append(endPosition, st.To)
+		}
+	}
+	h.begin(GOTO)
+	return endPosition, pathTaken, nil
+}
@@ -0,0 +1,160 @@
+package agent
+
+import (
+	"fmt"
+	"github.com/dark-enstein/chardot/internal/ilog"
+	"io"
+)
+
+// Opts configures a Hare as NewHare builds it. Options are applied in order, after the values found in the context,
+// so they take precedence over them. An Opts returns an error for a value it cannot accept.
+type Opts func(h *Hare) error
+
+// Bounds is the rectangle of the world an agent may move in, Min and Max included.
+type Bounds struct {
+	Min, Max Point
+}
+
+// Contains reports whether p lies within the Bounds.
+func (b Bounds) Contains(p Point) bool {
+	return p.X >= b.Min.X && p.X <= b.Max.X && p.Y >= b.Min.Y && p.Y <= b.Max.Y
+}
+
+// SpeedProfile is how fast an agent walks and runs.
+type SpeedProfile struct {
+	Walk, Run Speed
+}
+
+// WithPosition starts the Hare at p instead of the origin.
+func WithPosition(p Point) Opts {
+	return func(h *Hare) error {
+		h.pos = Point{X: p.X, Y: p.Y}
+		return nil
+	}
+}
+
+// WithName names the Hare, AGENT otherwise.
+func WithName(name string) Opts {
+	return func(h *Hare) error {
+		if name == "" {
+			return fmt.Errorf("name is empty")
+		}
+		h.name = name
+		return nil
+	}
+}
+
+// WithReporter hands everything the Hare has to tell to r.
+func WithReporter(r Reporter) Opts {
+	return func(h *Hare) error {
+		if r == nil {
+			return fmt.Errorf("reporter is nil")
+		}
+		h.reporter = r
+		return nil
+	}
+}
+
+// WithWriter reports to w as text.
+func WithWriter(w io.Writer) Opts {
+	return func(h *Hare) error {
+		if w == nil {
+			return fmt.Errorf("writer is nil")
+		}
+		h.reporter = NewTextReporter(w)
+		return nil
+	}
+}
+
+// WithLogger makes the Hare log to l instead of the logger found in the context, or Clog.
+func WithLogger(l *ilog.Logger) Opts {
+	return func(h *Hare) error {
+		if l == nil {
+			return fmt.Errorf("logger is nil")
+		}
+		h.logger = l
+		return nil
+	}
+}
+
+// WithClock makes the Hare wait on c between paces.
+func WithClock(c Clock) Opts {
+	return func(h *Hare) error {
+		if c == nil {
+			return fmt.Errorf("clock is nil")
+		}
+		h.clock = c
+		return nil
+	}
+}
+
+// WithBounds keeps the Hare within b. A movement that would take it out stops short of the edge with
+// ErrOutOfBounds.
+func WithBounds(b Bounds) Opts {
+	return func(h *Hare) error {
+		if b.Min.X > b.Max.X || b.Min.Y > b.Max.Y {
+			return fmt.Errorf("bounds %v to %v are empty", b.Min, b.Max)
+		}
+		h.bounds = &Bounds{Min: Point{X: b.Min.X, Y: b.Min.Y}, Max: Point{X: b.Max.X, Y: b.Max.Y}}
+		return nil
+	}
+}
+
+// WithHeading sets the Direction the Hare faces before its first movement, NORTH otherwise.
+func WithHeading(d Direction) Opts {
+	return func(h *Hare) error {
+		if x, y := d.Unit(); x == 0 && y == 0 {
+			return fmt.Errorf("%w: heading %v is not a direction the Hare can face", ErrUnknownDirection, d)
+		}
+		h.heading = d
+		return nil
+	}
+}
+
+// WithWorld puts the Hare in w. A movement that would take it out of w, or into one of its obstacles, stops short of
+// it with ErrOutOfBounds or ErrBlocked, and MoveTo routes around the obstacles.
+func WithWorld(w *World) Opts {
+	return func(h *Hare) error {
+		if w == nil {
+			return fmt.Errorf("world is nil")
+		}
+		h.world = w
+		return nil
+	}
+}
+
+// WithGait makes the Hare walk or run each leg of a MoveTo as g picks, instead of walking them all.
+func WithGait(g Gait) Opts {
+	return func(h *Hare) error {
+		if g == nil {
+			return fmt.Errorf("gait is nil")
+		}
+		h.gait = g
+		return nil
+	}
+}
+
+// WithSpeedProfile sets the speeds of the Hare, overriding the ones passed to NewHare.
+func WithSpeedProfile(sp SpeedProfile) Opts {
+	return func(h *Hare) error {
+		h.nature.walk, h.nature.run = sp.Walk, sp.Run
+		return nil
+	}
+}
+
+// validate checks the Hare NewHare built from its options.
+func (h *Hare) validate() error {
+	if h.nature.walk < 0 {
+		return fmt.Errorf("%w: walk speed %d is negative", ErrInvalidSpeed, h.nature.walk)
+	}
+	if h.nature.run < 0 {
+		return fmt.Errorf("%w: run speed %d is negative", ErrInvalidSpeed, h.nature.run)
+	}
+	if h.bounds != nil && !h.bounds.Contains(h.pos) {
+		return fmt.Errorf("position (%v, %v) is out of bounds %v to %v", h.pos.X, h.pos.Y, h.bounds.Min, h.bounds.Max)
+	}
+	if h.world != nil && !h.world.Free(h.pos) {
+		return fmt.Errorf("position (%v, %v) is not free in the world", h.pos.X, h.pos.Y)
+	}
+	return nil
+}
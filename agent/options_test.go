@@ -0,0 +1,152 @@
+package agent_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/internal/ilog"
+)
+
+func TestNewHareOptions(t *testing.T) {
+	vc := agent.NewVirtualClock(time.Unix(0, 0))
+	vc.Join()
+	defer vc.Leave()
+	var out bytes.Buffer
+
+	h, err := agent.NewHare(context.Background(), 1, 1,
+		agent.WithClock(vc),
+		agent.WithWriter(&out),
+		agent.WithName("bugs"),
+		agent.WithPosition(agent.Point{X: 2, Y: 3}),
+		agent.WithBounds(agent.Bounds{Min: agent.Point{X: -5, Y: -5}, Max: agent.Point{X: 5, Y: 5}}),
+		agent.WithHeading(agent.SOUTH),
+		agent.WithSpeedProfile(agent.SpeedProfile{Walk: 1, Run: 4}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Name(); got != "bugs" {
+		t.Errorf("Name() = %q, want bugs", got)
+	}
+	if got := h.Heading(); got != agent.SOUTH {
+		t.Errorf("Heading() = %v, want SOUTH", got)
+	}
+	if got, want := h.Position(), (agent.Point{X: 2, Y: 3}); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+
+	// running north at 4 leaves the bounds on the second pace
+	err = h.Run(3*time.Second, agent.NORTH)
+	var pme *agent.PartialMoveError
+	if !errors.As(err, &pme) || !errors.Is(err, agent.ErrOutOfBounds) {
+		t.Fatalf("Run() = %v, want a PartialMoveError out of bounds", err)
+	}
+	if pme.Completed != 0 {
+		t.Errorf("completed %d paces, want 0", pme.Completed)
+	}
+	if err := h.Walk(2*time.Second, agent.NORTH); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.Position(), (agent.Point{X: 2, Y: 5}); got != want {
+		t.Errorf("Position() = %v, want %v", got, want)
+	}
+	if got := h.Heading(); got != agent.NORTH {
+		t.Errorf("Heading() = %v after walking north", got)
+	}
+	if err := h.Move(4, 0); !errors.Is(err, agent.ErrOutOfBounds) {
+		t.Errorf("Move() = %v, want out of bounds", err)
+	}
+	if err := h.Move(-3, -1); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.Heading(); got != agent.SOUTHEAST {
+		t.Errorf("Heading() = %v after moving by (-3, -1), want SOUTHEAST", got)
+	}
+	if !strings.Contains(out.String(), "Walked from") {
+		t.Errorf("writer did not receive the report:\n%s", out.String())
+	}
+}
+
+func TestNewHareRejectsInvalidOptions(t *testing.T) {
+	ctx := context.WithValue(context.Background(), agent.REPORTERCTX, agent.SilentReporter{})
+	for name, opts := range map[string][]agent.Opts{
+		"empty name":      {agent.WithName("")},
+		"nil clock":       {agent.WithClock(nil)},
+		"nil reporter":    {agent.WithReporter(nil)},
+		"empty bounds":    {agent.WithBounds(agent.Bounds{Min: agent.Point{X: 1}})},
+		"out of bounds":   {agent.WithPosition(agent.Point{X: 9}), agent.WithBounds(agent.Bounds{Max: agent.Point{X: 5, Y: 5}})},
+		"still heading":   {agent.WithHeading(agent.STILL)},
+		"negative speeds": {agent.WithSpeedProfile(agent.SpeedProfile{Walk: -1, Run: 2})},
+	} {
+		if h, err := agent.NewHare(ctx, 1, 2, opts...); err == nil || h != nil {
+			t.Errorf("%s: NewHare() = %v, %v, want an error", name, h, err)
+		}
+	}
+}
+
+func TestWithLoggerKeepsToTheHare(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+	level := agent.Clog.Level()
+
+	quiet, err := ilog.NewLogger("ERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	loud, err := ilog.NewLogger("INFO")
+	if err != nil {
+		t.Fatal(err)
+	}
+	silent := agent.WithReporter(agent.SilentReporter{})
+	q, err := agent.NewHare(context.Background(), 1, 1, agent.WithLogger(quiet), silent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := agent.NewHare(context.WithValue(context.Background(), ilog.LOGGERCTX, loud), 1, 1, silent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := agent.Clog.Level(); got != level {
+		t.Errorf("Clog level changed from %d to %d", level, got)
+	}
+
+	if err := q.Move(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "Set action") {
+		t.Errorf("the Hare logging at ERROR logged %q", out.String())
+	}
+	if err := l.Move(1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "Set action") {
+		t.Errorf("the Hare logging at INFO logged nothing")
+	}
+}
+
+func TestQuietHareLogsNoPaces(t *testing.T) {
+	var out bytes.Buffer
+	log.SetOutput(&out)
+	defer log.SetOutput(os.Stderr)
+
+	h, vc := newTestHare(t, 2, 3, agent.WithReporter(agent.SilentReporter{}))
+	vc.Join()
+	defer vc.Leave()
+	if err := h.Walk(2*time.Second, agent.NORTHEAST); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Run(time.Second, agent.WEST); err != nil {
+		t.Fatal(err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("a Hare logging at ERROR logged its paces:\n%s", out.String())
+	}
+}
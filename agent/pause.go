@@ -0,0 +1,61 @@
+package agent
+
+import (
+	"context"
+)
+
+// Pauser is implemented by agents whose movements can be paused and resumed, such as Hare.
+type Pauser interface {
+	Pause()
+	Resume()
+	Paused() bool
+}
+
+// Pause holds the Hare in place. A movement in progress stops once its current pace is taken, and picks up again,
+// pace by pace, on Resume. The context of the movement still applies while it is paused. A movement run by a
+// Simulation on a VirtualClock leaves the clock while it is paused, so the other agents of the Simulation go on,
+// and joins it again on Resume.
+func (h *Hare) Pause() {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.gate == nil {
+		h.gate = make(chan struct{})
+	}
+}
+
+// Resume lets a paused Hare move again.
+func (h *Hare) Resume() {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if h.gate != nil {
+		close(h.gate)
+		h.gate = nil
+	}
+}
+
+// Paused reports whether the Hare is paused.
+func (h *Hare) Paused() bool {
+	h.m.Lock()
+	defer h.m.Unlock()
+	return h.gate != nil
+}
+
+// hold blocks while the Hare is paused, or until ctx is done.
+func (h *Hare) hold(ctx context.Context) error {
+	h.m.Lock()
+	gate := h.gate
+	h.m.Unlock()
+	if gate == nil {
+		return nil
+	}
+	if vc, ok := ctx.Value(participantCTX).(*VirtualClock); ok && vc == h.clock {
+		vc.Leave()
+		defer vc.Join()
+	}
+	select {
+	case <-gate:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,64 @@
+package agent_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+)
+
+func TestPausedHareLetsTheSimulationGoOn(t *testing.T) {
+	ctx := raceContext(t)
+	vc := ctx.Value(agent.CLOCKCTX).(*agent.VirtualClock)
+	moving, err := agent.NewHare(ctx, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paused, err := agent.NewHare(ctx, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := agent.NewSimulation(ctx)
+	for name, h := range map[string]*agent.Hare{"moving": moving, "paused": paused} {
+		if err := sim.Register(name, h); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	paused.Pause()
+	arrived := make(chan struct{})
+	walk := func(secs int, done chan struct{}) agent.Work {
+		return func(ctx context.Context, a agent.Agent) error {
+			err := a.(*agent.Hare).WalkContext(ctx, time.Duration(secs)*time.Second, agent.NORTH)
+			if done != nil {
+				close(done)
+			}
+			return err
+		}
+	}
+	ran := make(chan error)
+	go func() {
+		ran <- sim.Run(context.Background(), map[string]agent.Work{"moving": walk(3, arrived), "paused": walk(2, nil)})
+	}()
+
+	select {
+	case <-arrived:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the moving Hare stalled while the other was paused")
+	}
+	if got := paused.Position(); got != (agent.Point{}) {
+		t.Errorf("the paused Hare moved to %v", got)
+	}
+	paused.Resume()
+	if err := <-ran; err != nil {
+		t.Fatal(err)
+	}
+	// the paused Hare sets off once resumed, after the 3s of the moving one
+	if got, want := paused.Position(), (agent.Point{Y: 4}); got != want {
+		t.Errorf("the resumed Hare ended at %v, want %v", got, want)
+	}
+	if got := vc.Now().Sub(time.Unix(0, 0)); got != 5*time.Second {
+		t.Errorf("the simulation took %v, want 5s", got)
+	}
+}
@@ -0,0 +1,137 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Racer is an Agent that can take part in a Race.
+type Racer interface {
+	Agent
+	Tracked
+	// Stride covers ground toward dir the way the Racer naturally would, for about one second.
+	Stride(ctx context.Context, dir Direction) error
+}
+
+// Stride runs for one second.
+func (h *Hare) Stride(ctx context.Context, dir Direction) error {
+	return h.RunContext(ctx, time.Second, dir)
+}
+
+// Race sends every agent of a Simulation toward a finish line, Finish away from where each of them starts, in Direction.
+type Race struct {
+	Finish    float64
+	Direction Direction
+	Limit     time.Duration // Limit is the race time after which the racers still out give up. It must be positive.
+}
+
+// Result is how one racer did in a Race.
+type Result struct {
+	Rank     int
+	Name     string
+	Finished bool
+	Time     time.Duration // Time is when the racer crossed the finish line, or how long it raced if it did not.
+	Distance float64       // Distance is how far toward the finish line the racer got.
+	Err      error
+}
+
+// Run races every agent of sim concurrently on its shared Clock and returns the results ranked: finishers by time,
+// then the others by distance. Every agent must be a Racer. A racer whose stride neither moves it nor takes any time
+// is stopped, since it would never finish nor reach the Limit.
+func (r *Race) Run(ctx context.Context, sim *Simulation) ([]Result, error) {
+	x, y := r.Direction.Unit()
+	if x == 0 && y == 0 {
+		return nil, fmt.Errorf("cannot race toward %v", r.Direction)
+	}
+	norm := math.Hypot(float64(x), float64(y))
+	ux, uy := float64(x)/norm, float64(y)/norm
+	if r.Finish <= 0 {
+		return nil, fmt.Errorf("finish line %v must be ahead of the start", r.Finish)
+	}
+	if r.Limit <= 0 {
+		return nil, fmt.Errorf("race limit %v must be positive", r.Limit)
+	}
+	work := make(map[string]Work)
+	results := make(map[string]*Result)
+	var m sync.Mutex
+	for _, name := range sim.Names() {
+		a, _ := sim.Agent(name)
+		racer, ok := a.(Racer)
+		if !ok {
+			return nil, fmt.Errorf("agent %s cannot race", name)
+		}
+		res := &Result{Name: name}
+		results[name] = res
+		work[name] = func(ctx context.Context, _ Agent) error {
+			clock := sim.Clock()
+			start, from := clock.Now(), racer.Position()
+			progress := func() float64 {
+				at := racer.Position()
+				return float64(at.X-from.X)*ux + float64(at.Y-from.Y)*uy
+			}
+			var err error
+			for {
+				before, t0 := progress(), clock.Now()
+				if t0.Sub(start) >= r.Limit {
+					break
+				}
+				if err = racer.Stride(ctx, r.Direction); err != nil {
+					break
+				}
+				after, t1 := progress(), clock.Now()
+				if after == before && !t1.After(t0) {
+					err = fmt.Errorf("stride took no time and made no progress")
+					break
+				}
+				if after >= r.Finish {
+					// interpolate when the line was crossed within the stride
+					crossed := t0.Add(time.Duration(float64(t1.Sub(t0)) * (r.Finish - before) / (after - before)))
+					m.Lock()
+					res.Finished, res.Time, res.Distance = true, crossed.Sub(start), after
+					m.Unlock()
+					return nil
+				}
+			}
+			m.Lock()
+			res.Time, res.Distance, res.Err = clock.Now().Sub(start), progress(), err
+			m.Unlock()
+			return err
+		}
+	}
+	err := sim.Run(ctx, work)
+
+	ranked := make([]Result, 0, len(results))
+	for _, res := range results {
+		ranked = append(ranked, *res)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		switch {
+		case a.Finished != b.Finished:
+			return a.Finished
+		case a.Finished && a.Time != b.Time:
+			return a.Time < b.Time
+		case !a.Finished && a.Distance != b.Distance:
+			return a.Distance > b.Distance
+		}
+		return a.Name < b.Name
+	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+	}
+	return ranked, err
+}
+
+// PrintStandings prints ranked Race results, one racer per line.
+func PrintStandings(results []Result) {
+	ReportStandings(defaultReporter, results)
+}
+
+// ReportStandings hands ranked Race results to rep.
+func ReportStandings(rep Reporter, results []Result) {
+	rep.Report(Report{Kind: REPORTSTANDINGS, Results: results})
+}
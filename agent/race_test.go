@@ -0,0 +1,114 @@
+package agent_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/internal/ilog"
+)
+
+// raceContext returns a context carrying a quiet logger, a silent reporter and a fresh VirtualClock.
+func raceContext(t *testing.T) context.Context {
+	t.Helper()
+	logger, err := ilog.NewLogger("ERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.WithValue(context.Background(), ilog.LOGGERCTX, logger)
+	ctx = context.WithValue(ctx, agent.CLOCKCTX, agent.NewVirtualClock(time.Unix(0, 0)))
+	return context.WithValue(ctx, agent.REPORTERCTX, agent.SilentReporter{})
+}
+
+// frozen is a Racer whose stride neither moves it nor takes any time.
+type frozen struct {
+	*agent.Hare
+}
+
+func (frozen) Stride(context.Context, agent.Direction) error {
+	return nil
+}
+
+func newSimulation(t *testing.T, ctx context.Context, racers map[string]agent.Agent) *agent.Simulation {
+	t.Helper()
+	sim := agent.NewSimulation(ctx)
+	for _, name := range []string{"hare", "tortoise", "stuck", "frozen"} {
+		if a, ok := racers[name]; ok {
+			if err := sim.Register(name, a); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	return sim
+}
+
+func TestRaceRequiresLimit(t *testing.T) {
+	ctx := raceContext(t)
+	tortoise, err := agent.NewTortoise(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := newSimulation(t, ctx, map[string]agent.Agent{"tortoise": tortoise})
+	race := &agent.Race{Finish: 10, Direction: agent.NORTH}
+	if _, err := race.Run(ctx, sim); err == nil {
+		t.Fatal("Run() with no Limit succeeded, want an error")
+	}
+	race = &agent.Race{Finish: 10, Direction: agent.STILL, Limit: time.Second}
+	if _, err := race.Run(ctx, sim); err == nil {
+		t.Fatal("Run() toward STILL succeeded, want an error")
+	}
+}
+
+func TestRaceStopsRacersThatNeverProgress(t *testing.T) {
+	ctx := raceContext(t)
+	hare, err := agent.NewHare(ctx, 2, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stuck, err := agent.NewTortoise(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	still, err := agent.NewHare(ctx, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sim := newSimulation(t, ctx, map[string]agent.Agent{"hare": hare, "stuck": stuck, "frozen": frozen{still}})
+	race := &agent.Race{Finish: 10, Direction: agent.NORTH, Limit: 30 * time.Second}
+
+	done := make(chan []agent.Result)
+	go func() {
+		results, _ := race.Run(ctx, sim)
+		done <- results
+	}()
+	var results []agent.Result
+	select {
+	case results = <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("the race did not end")
+	}
+
+	want := []struct {
+		name     string
+		finished bool
+		time     time.Duration
+		failed   bool
+	}{
+		{"hare", true, 2 * time.Second, false},
+		{"frozen", false, 0, true},
+		{"stuck", false, 30 * time.Second, false},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("got %d results, want %d: %+v", len(results), len(want), results)
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Rank != i+1 || r.Name != w.name || r.Finished != w.finished || r.Time != w.time || (r.Err != nil) != w.failed {
+			t.Errorf("result %d = %+v, want %s finished=%v time=%v failed=%v", i+1, r, w.name, w.finished, w.time, w.failed)
+		}
+		if !r.Finished && r.Distance != 0 {
+			t.Errorf("%s got %v toward the line, want 0", r.Name, r.Distance)
+		}
+	}
+}
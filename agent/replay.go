@@ -0,0 +1,129 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"github.com/dark-enstein/chardot/internal/ilog"
+	"math"
+	"time"
+)
+
+// Sample is a position an agent was recorded at, and when.
+type Sample struct {
+	Time time.Time
+	At   Point
+}
+
+// Recording is the track of one agent, as Samples in the order they were recorded.
+type Recording struct {
+	Agent   string // Agent is the name the track was recorded under, if it had one.
+	Samples []Sample
+}
+
+// Validate returns an error wrapping ErrInvalidRecording if the Recording has no Samples, or a Sample without a
+// time or recorded before the one preceding it.
+func (r Recording) Validate() error {
+	if len(r.Samples) == 0 {
+		return fmt.Errorf("%w: no samples", ErrInvalidRecording)
+	}
+	for i, s := range r.Samples {
+		if s.Time.IsZero() {
+			return fmt.Errorf("%w: sample %d has no time", ErrInvalidRecording, i)
+		}
+		if i > 0 && s.Time.Before(r.Samples[i-1].Time) {
+			return fmt.Errorf("%w: sample %d at %v is earlier than the one before it", ErrInvalidRecording, i, s.Time)
+		}
+	}
+	return nil
+}
+
+// Duration returns the time from the first Sample of the Recording to its last.
+func (r Recording) Duration() time.Duration {
+	if len(r.Samples) == 0 {
+		return 0
+	}
+	return r.Samples[len(r.Samples)-1].Time.Sub(r.Samples[0].Time)
+}
+
+// Replayer is implemented by agents that can be driven along a Recording, such as Hare.
+type Replayer interface {
+	ReplayContext(ctx context.Context, rec Recording) error
+}
+
+// Replay drives the Agent along rec. The Hare starts from where it stands, as if it stood at the first Sample, and
+// takes one pace per following Sample, by the displacement between the two, lasting as long as the time between
+// them on its Clock. On the RealClock the Recording is replayed at its original pace; on a VirtualClock it is
+// fast-forwarded. Paces are recorded in the Path as REPLAY Steps, at the Speed the Sample was reached at.
+func (h *Hare) Replay(rec Recording) error {
+	return h.ReplayContext(h.ctx, rec)
+}
+
+// ReplayContext is Replay bound to ctx instead of the Hare's own context. If ctx is cancelled or its deadline
+// passes, the Hare stops at the last completed pace and A *PartialMoveError is returned.
+func (h *Hare) ReplayContext(ctx context.Context, rec Recording) error {
+	return h.ReplayAsync(ctx, rec).Wait()
+}
+
+// ReplayAsync queues A Replay bound to ctx and returns its Handle at once.
+func (h *Hare) ReplayAsync(ctx context.Context, rec Recording) *Handle {
+	total := len(rec.Samples) - 1
+	if total < 0 {
+		total = 0
+	}
+	return h.enqueue(REPLAY, total, func(hd *Handle) error {
+		former := h.begin(REPLAY)
+		posStack, dist, err := h.replay(ctx, rec, hd)
+		h.report(Report{Kind: REPORTEND, Action: REPLAY, From: former, At: h.Position(), Path: dist, Positions: posStack, Err: err})
+		return err
+	})
+}
+
+// replay moves the Hare along rec, the way flow moves it in one Direction. It is only run by the queue worker.
+func (h *Hare) replay(ctx context.Context, rec Recording, hd *Handle) ([]Point, *Path, error) {
+	if err := rec.Validate(); err != nil {
+		return nil, nil, err
+	}
+	total := len(rec.Samples) - 1
+	endPosition := make([]Point, 0, total)
+	pathTaken := &Path{}
+	from := h.Position()
+
+	h.report(Report{Kind: REPORTSTART, Action: REPLAY, From: from})
+	for i := 1; i <= total; i++ {
+		prev, next := rec.Samples[i-1], rec.Samples[i]
+		pace := paceBetween(prev.At, next.At)
+		tick := next.Time.Sub(prev.Time)
+		var s Speed
+		if tick > 0 {
+			s = Speed(math.Round(prev.At.Distance(&next.At) / tick.Seconds()))
+		}
+		st, err := h.takePace(ctx, pace, s, tick, hd)
+		if err != nil {
+			h.logger.Log(ilog.ERROR, "Replay ended prematurely. Only completed %d out of %d paces.", i-1, total)
+			return endPosition, pathTaken, &PartialMoveError{
+				Action:    REPLAY,
+				Direction: pace.d,
+				Completed: i - 1,
+				Total:     total,
+				From:      from,
+				At:        h.Position(),
+				Err:       err,
+			}
+		}
+		pathTaken.M = append(pathTaken.M, *pace.PMap())
+		pathTaken.A = append(pathTaken.A, *pace)
+		pathTaken.S = append(pathTaken.S, st)
+		endPosition = append(endPosition, st.To)
+	}
+	return endPosition, pathTaken, nil
+}
+
+// paceBetween returns the Pace that displaces from p to q, in the Direction of the displacement, or STILL.
+func paceBetween(p, q Point) *Pace {
+	x, y := q.X-p.X, q.Y-p.Y
+	d, ok := headingOf(x, y)
+	if !ok {
+		d = STILL
+	}
+	return &Pace{x: x, y: y, d: d}
+}
@@ -0,0 +1,84 @@
+package agent_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+)
+
+func TestImportAndReplay(t *testing.T) {
+	recs, err := agent.ImportCSV(strings.NewReader("timestamp,x,y\n" +
+		"10,5,5\n" +
+		"12,5,9\n" +
+		"12.5,3,9\n" +
+		"15.5,3,9\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || len(recs[0].Samples) != 4 || recs[0].Duration() != 5500*time.Millisecond {
+		t.Fatalf("ImportCSV() = %+v", recs)
+	}
+
+	h, vc := newTestHare(t, 1, 1)
+	vc.Join()
+	defer vc.Leave()
+	if err := h.Replay(recs[0]); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := h.Position(), (agent.Point{X: -2, Y: 4}); got != want {
+		t.Errorf("Position() = %v after replay, want %v", got, want)
+	}
+	steps := h.Path().S
+	if len(steps) != 3 {
+		t.Fatalf("replay recorded %d steps, want 3", len(steps))
+	}
+	for i, want := range []struct {
+		start, end time.Duration
+		speed      agent.Speed
+	}{
+		{0, 2 * time.Second, 2},
+		{2 * time.Second, 2500 * time.Millisecond, 4},
+		{2500 * time.Millisecond, 5500 * time.Millisecond, 0},
+	} {
+		st := steps[i]
+		if st.Action != agent.REPLAY || !st.Start.Equal(time.Unix(0, 0).Add(want.start)) || !st.End.Equal(time.Unix(0, 0).Add(want.end)) || st.Speed != want.speed {
+			t.Errorf("step %d = %+v, want from %v to %v at %d", i, st, want.start, want.end, want.speed)
+		}
+	}
+
+	// what a Hare exports, it can replay
+	var js bytes.Buffer
+	if err := agent.ExportJSON(&js, h.Track()); err != nil {
+		t.Fatal(err)
+	}
+	back, err := agent.Import(&js, agent.JSONEXPORT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != 1 || len(back[0].Samples) != 4 || back[0].Duration() != recs[0].Duration() || back[0].Samples[3].At != h.Position() {
+		t.Errorf("Import(ExportJSON()) = %+v", back)
+	}
+
+	gpx := `<gpx><trk><name>field</name><trkseg>
+		<trkpt lat="0" lon="0"><time>2024-01-01T00:00:00Z</time></trkpt>
+		<trkpt lat="0.001" lon="0.001"><time>2024-01-01T00:01:00Z</time></trkpt>
+	</trkseg></trk></gpx>`
+	recs, err = agent.Import(strings.NewReader(gpx), agent.GPXIMPORT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || recs[0].Agent != "field" || recs[0].Samples[1].At != (agent.Point{X: -111, Y: 111}) {
+		t.Errorf("ImportGPX() = %+v", recs)
+	}
+
+	if err := h.Replay(agent.Recording{}); !errors.Is(err, agent.ErrInvalidRecording) {
+		t.Errorf("Replay(empty) = %v, want %v", err, agent.ErrInvalidRecording)
+	}
+	if _, err := agent.ImportCSV(strings.NewReader("when,x,y\n1,0,0\n")); !errors.Is(err, agent.ErrInvalidRecording) {
+		t.Errorf("ImportCSV(no timestamp) = %v, want %v", err, agent.ErrInvalidRecording)
+	}
+}
@@ -0,0 +1,355 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/dark-enstein/chardot/internal/ilog"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+var REPORTERCTX = "REPORTERCTX" // REPORTERCTX is the context key under which the Reporter used by agents is stored.
+
+// ReportKind is the kind of a Report.
+type ReportKind int
+
+const (
+	REPORTORIGIN    ReportKind = iota // REPORTORIGIN is reported when an agent is created.
+	REPORTSTART                       // REPORTSTART is reported when a movement starts travelling.
+	REPORTPACE                        // REPORTPACE is reported for every pace a movement takes.
+	REPORTEND                         // REPORTEND is reported when a movement ends, with the Path it took.
+	REPORTSUMMARY                     // REPORTSUMMARY is reported with the whole Path of an agent, or of several combined.
+	REPORTSTANDINGS                   // REPORTSTANDINGS is reported with the ranked Results of a Race.
+	REPORTSTATS                       // REPORTSTATS is reported with the Stats of the Path of an agent.
+)
+
+// String returns the name of the ReportKind.
+func (k ReportKind) String() string {
+	switch k {
+	case REPORTORIGIN:
+		return "origin"
+	case REPORTSTART:
+		return "start"
+	case REPORTPACE:
+		return "pace"
+	case REPORTEND:
+		return "end"
+	case REPORTSUMMARY:
+		return "summary"
+	case REPORTSTANDINGS:
+		return "standings"
+	case REPORTSTATS:
+		return "stats"
+	}
+	return "report kind unrecognized"
+}
+
+// Report is something an agent, a Simulation or a Race has to tell. Only the fields relevant to its Kind are set.
+type Report struct {
+	Kind      ReportKind
+	Time      time.Time // Time is the time on the Clock of the agent, zero for reports that are not tied to one.
+	Agent     string    // Agent is the name of the agent, empty for a summary of several agents.
+	Action    MovType
+	Speed     Speed
+	Pace      Pace
+	From, At  Point   // From is where the movement started, At where the agent is.
+	Path      *Path   // Path is the Path of the movement, for REPORTEND, or of the agent, for REPORTSUMMARY.
+	Positions []Point // Positions are the positions Path took the agent through.
+	Agents    int     // Agents is the number of agents combined in a REPORTSUMMARY without Agent.
+	Results   []Result
+	Stats     *Stats
+	Err       error
+}
+
+// Reporter receives the Reports of agents. Hare writes nothing to stdout itself; everything it has to tell goes to
+// its Reporter. A Reporter must be safe for concurrent use, as several agents may share it.
+type Reporter interface {
+	Report(r Report)
+}
+
+// GetReporterFromCtx returns the Reporter stored in the context under REPORTERCTX.
+func GetReporterFromCtx(ctx context.Context) (Reporter, error) {
+	r, ok := ctx.Value(REPORTERCTX).(Reporter)
+	if !ok {
+		return nil, fmt.Errorf("reporter not found in context")
+	}
+	return r, nil
+}
+
+// TextReporter writes Reports to W as human-readable text. It is the default Reporter of a Hare, writing to
+// os.Stdout.
+type TextReporter struct {
+	W io.Writer
+	m sync.Mutex
+}
+
+// NewTextReporter returns a TextReporter writing to w.
+func NewTextReporter(w io.Writer) *TextReporter {
+	return &TextReporter{W: w}
+}
+
+// Report writes r as text.
+func (t *TextReporter) Report(r Report) {
+	t.m.Lock()
+	defer t.m.Unlock()
+	w := t.W
+	switch r.Kind {
+	case REPORTORIGIN:
+		fprintPathTaken(w, ORIGIN, nil, nil)
+	case REPORTSTART:
+		fprintln(w, 0, "Travelling...")
+	case REPORTPACE:
+		fprintln(w, 0, "pace: %v, speed: %d", &r.Pace, r.Speed.Int())
+	case REPORTEND:
+		if r.Action != MOVE && r.Err == nil {
+			fprintln(w, 0, "Travel complete")
+		}
+		switch r.Action {
+		case WALK:
+			fprintln(w, 1, "Walked from %v to %v", r.From, r.At)
+		case RUN:
+			fprintln(w, 0, "Ran from %v to %v", r.From, r.At)
+		case WAIT:
+			fprintln(w, 0, "Waited at %v", r.From)
+		case REPLAY:
+			fprintln(w, 0, "Replayed from %v to %v", r.From, r.At)
+		case GOTO:
+			fprintln(w, 0, "Went from %v to %v", r.From, r.At)
+		}
+		fprintPathTaken(w, r.Action, r.Path, r.Positions)
+	case REPORTSUMMARY:
+		if r.Agent == "" {
+			fprintln(w, 0, "COMBINED: %d paces across %d agents", len(r.Path.A), r.Agents)
+			return
+		}
+		fprintln(w, 0, "AGENT %s", r.Agent)
+		fprintPathTaken(w, TOTAL, r.Path, append(r.Positions, r.At))
+		if r.Err != nil {
+			fprintln(w, 0, "ERROR: %v", r.Err)
+		}
+	case REPORTSTANDINGS:
+		fprintln(w, 0, "STANDINGS")
+		for _, res := range r.Results {
+			status := "DNF"
+			if res.Finished {
+				status = "FINISHED"
+			}
+			fprintln(w, 0, "%d. %s\t%s\ttime: %v\tdistance: %.2f", res.Rank, res.Name, status, res.Time, res.Distance)
+		}
+	case REPORTSTATS:
+		fprintStats(w, r.Agent, r.Stats)
+	}
+}
+
+// JSONReporter writes Reports to W as JSON, one object per line.
+type JSONReporter struct {
+	W io.Writer
+	m sync.Mutex
+}
+
+// NewJSONReporter returns a JSONReporter writing to w.
+func NewJSONReporter(w io.Writer) *JSONReporter {
+	return &JSONReporter{W: w}
+}
+
+// jsonPace is the JSON form of a Pace, along with the Step it was recorded as when there is one.
+type jsonPace struct {
+	Direction string     `json:"direction"`
+	X         Coordinate `json:"x"`
+	Y         Coordinate `json:"y"`
+	Seq       *int       `json:"seq,omitempty"`
+	Start     *time.Time `json:"start,omitempty"`
+	End       *time.Time `json:"end,omitempty"`
+	Action    string     `json:"action,omitempty"`
+	Speed     *int       `json:"speed,omitempty"`
+	From      *jsonPoint `json:"from,omitempty"`
+	To        *jsonPoint `json:"to,omitempty"`
+}
+
+// jsonPoint is the JSON form of a Point.
+type jsonPoint struct {
+	X Coordinate `json:"x"`
+	Y Coordinate `json:"y"`
+}
+
+// jsonResult is the JSON form of a Result.
+type jsonResult struct {
+	Rank     int     `json:"rank"`
+	Name     string  `json:"name"`
+	Finished bool    `json:"finished"`
+	Seconds  float64 `json:"seconds"`
+	Distance float64 `json:"distance"`
+	Err      string  `json:"error,omitempty"`
+}
+
+// jsonStats is the JSON form of Stats. Durations are in seconds and the time spent per action is keyed by its name.
+type jsonStats struct {
+	Paces            int                `json:"paces"`
+	Distance         float64            `json:"distance"`
+	Displacement     jsonPoint          `json:"displacement"`
+	NetDistance      float64            `json:"netDistance"`
+	Bearing          float64            `json:"bearing"`
+	Min              jsonPoint          `json:"min"`
+	Max              jsonPoint          `json:"max"`
+	Seconds          float64            `json:"seconds"`
+	SecondsByAction  map[string]float64 `json:"secondsByAction,omitempty"`
+	AverageSpeed     float64            `json:"averageSpeed"`
+	MaxSpeed         float64            `json:"maxSpeed"`
+	DirectionChanges int                `json:"directionChanges"`
+	TurningPoints    []jsonPoint        `json:"turningPoints,omitempty"`
+}
+
+func newJSONStats(st Stats) *jsonStats {
+	js := &jsonStats{
+		Paces:            st.Paces,
+		Distance:         st.Distance,
+		Displacement:     *newJSONPoint(st.Displacement),
+		NetDistance:      st.NetDistance,
+		Bearing:          st.Bearing,
+		Min:              *newJSONPoint(st.Bounds.Min),
+		Max:              *newJSONPoint(st.Bounds.Max),
+		Seconds:          st.Elapsed.Seconds(),
+		AverageSpeed:     st.AverageSpeed,
+		MaxSpeed:         st.MaxSpeed,
+		DirectionChanges: st.DirectionChanges,
+	}
+	for action, d := range st.TimeByAction {
+		if js.SecondsByAction == nil {
+			js.SecondsByAction = make(map[string]float64)
+		}
+		js.SecondsByAction[action.String()] = d.Seconds()
+	}
+	for _, p := range st.TurningPoints {
+		js.TurningPoints = append(js.TurningPoints, *newJSONPoint(p))
+	}
+	return js
+}
+
+// jsonReport is the JSON form of a Report.
+type jsonReport struct {
+	Kind      string       `json:"kind"`
+	Time      *time.Time   `json:"time,omitempty"`
+	Agent     string       `json:"agent,omitempty"`
+	Action    string       `json:"action,omitempty"`
+	Speed     *int         `json:"speed,omitempty"`
+	Pace      *jsonPace    `json:"pace,omitempty"`
+	From      *jsonPoint   `json:"from,omitempty"`
+	At        *jsonPoint   `json:"at,omitempty"`
+	Path      []jsonPace   `json:"path,omitempty"`
+	Positions []jsonPoint  `json:"positions,omitempty"`
+	Agents    int          `json:"agents,omitempty"`
+	Results   []jsonResult `json:"results,omitempty"`
+	Stats     *jsonStats   `json:"stats,omitempty"`
+	Err       string       `json:"error,omitempty"`
+}
+
+func newJSONPace(p Pace) jsonPace {
+	return jsonPace{Direction: p.d.String(), X: p.x, Y: p.y}
+}
+
+func newJSONStep(st Step) jsonPace {
+	jp := newJSONPace(st.Pace)
+	seq, speed, start, end := st.Seq, int(st.Speed), st.Start, st.End
+	jp.Seq, jp.Start, jp.End, jp.Action, jp.Speed = &seq, &start, &end, st.Action.String(), &speed
+	jp.From, jp.To = newJSONPoint(st.From), newJSONPoint(st.To)
+	return jp
+}
+
+// newJSONPath returns the JSON form of the paces of p, as Steps when p holds them.
+func newJSONPath(p *Path) []jsonPace {
+	jps := make([]jsonPace, 0, len(p.A))
+	for i, pace := range p.A {
+		if len(p.S) == len(p.A) {
+			jps = append(jps, newJSONStep(p.S[i]))
+			continue
+		}
+		jps = append(jps, newJSONPace(pace))
+	}
+	return jps
+}
+
+func newJSONPoint(p Point) *jsonPoint {
+	return &jsonPoint{X: p.X, Y: p.Y}
+}
+
+// Report writes r as a line of JSON.
+func (j *JSONReporter) Report(r Report) {
+	jr := jsonReport{Kind: r.Kind.String(), Agent: r.Agent}
+	if !r.Time.IsZero() {
+		jr.Time = &r.Time
+	}
+	if r.Err != nil {
+		jr.Err = r.Err.Error()
+	}
+	switch r.Kind {
+	case REPORTORIGIN:
+		jr.At = newJSONPoint(r.At)
+	case REPORTSTART:
+		jr.Action, jr.From = r.Action.String(), newJSONPoint(r.From)
+	case REPORTPACE:
+		speed, pace := int(r.Speed), newJSONPace(r.Pace)
+		jr.Action, jr.Speed, jr.Pace, jr.At = r.Action.String(), &speed, &pace, newJSONPoint(r.At)
+	case REPORTEND, REPORTSUMMARY:
+		if r.Kind == REPORTEND {
+			jr.Action, jr.From = r.Action.String(), newJSONPoint(r.From)
+		}
+		if r.Agent != "" || r.Kind == REPORTEND {
+			jr.At = newJSONPoint(r.At)
+		}
+		jr.Agents = r.Agents
+		if r.Path != nil {
+			jr.Path = newJSONPath(r.Path)
+		}
+		for _, p := range r.Positions {
+			jr.Positions = append(jr.Positions, *newJSONPoint(p))
+		}
+	case REPORTSTANDINGS:
+		jr.Results = make([]jsonResult, 0, len(r.Results))
+		for _, res := range r.Results {
+			jres := jsonResult{Rank: res.Rank, Name: res.Name, Finished: res.Finished, Seconds: res.Time.Seconds(), Distance: res.Distance}
+			if res.Err != nil {
+				jres.Err = res.Err.Error()
+			}
+			jr.Results = append(jr.Results, jres)
+		}
+	case REPORTSTATS:
+		if r.Stats != nil {
+			jr.Stats = newJSONStats(*r.Stats)
+		}
+	}
+	data, err := json.Marshal(jr)
+	if err != nil {
+		Clog.Log(ilog.ERROR, "encoding %v report: %v", r.Kind, err)
+		return
+	}
+	j.m.Lock()
+	defer j.m.Unlock()
+	j.W.Write(append(data, '\n'))
+}
+
+// SilentReporter discards every Report.
+type SilentReporter struct{}
+
+// Report does nothing.
+func (SilentReporter) Report(Report) {}
+
+// MultiReporter hands every Report to each of its Reporters, in order.
+type MultiReporter []Reporter
+
+// NewMultiReporter returns a MultiReporter handing Reports to rs.
+func NewMultiReporter(rs ...Reporter) MultiReporter {
+	return MultiReporter(rs)
+}
+
+// Report hands r to every Reporter.
+func (mr MultiReporter) Report(r Report) {
+	for _, rep := range mr {
+		rep.Report(r)
+	}
+}
+
+// defaultReporter is the Reporter of agents created without one.
+var defaultReporter Reporter = NewTextReporter(os.Stdout)
@@ -0,0 +1,92 @@
+package agent_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/internal/ilog"
+)
+
+func TestHareReporters(t *testing.T) {
+	logger, err := ilog.NewLogger("ERROR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var text, js bytes.Buffer
+	vc := agent.NewVirtualClock(time.Unix(0, 0))
+	vc.Join()
+	defer vc.Leave()
+	ctx := context.WithValue(context.Background(), ilog.LOGGERCTX, logger)
+	ctx = context.WithValue(ctx, agent.CLOCKCTX, vc)
+	ctx = context.WithValue(ctx, agent.REPORTERCTX, agent.NewMultiReporter(
+		agent.NewTextReporter(&text),
+		agent.NewJSONReporter(&js),
+	))
+
+	h, err := agent.NewHare(ctx, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Walk(2*time.Second, agent.NORTH); err != nil {
+		t.Fatal(err)
+	}
+	if err := h.Move(-1, 0); err != nil {
+		t.Fatal(err)
+	}
+	h.Println()
+
+	for _, want := range []string{"ORIGIN", "Travelling...", "Walked from", "MOVED FORWARD BY 2", "MOVED LEFT BY 1", "AGENT agent", "X = -1"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text report lacks %q:\n%s", want, text.String())
+		}
+	}
+
+	var kinds []string
+	for _, line := range strings.Split(strings.TrimSpace(js.String()), "\n") {
+		var r struct {
+			Kind  string `json:"kind"`
+			Agent string `json:"agent"`
+			At    *struct {
+				X, Y int
+			} `json:"at"`
+		}
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		if r.Agent != "agent" {
+			t.Errorf("line %q reports agent %q", line, r.Agent)
+		}
+		kinds = append(kinds, r.Kind)
+		if r.Kind == "summary" && (r.At == nil || r.At.X != -1 || r.At.Y != 4) {
+			t.Errorf("summary at %v, want (-1, 4)", r.At)
+		}
+	}
+	want := []string{"origin", "start", "pace", "pace", "end", "end", "summary"}
+	if strings.Join(kinds, " ") != strings.Join(want, " ") {
+		t.Errorf("JSON reports %v, want %v", kinds, want)
+	}
+}
+
+func TestTextReporterStatsBreaksOutEveryAction(t *testing.T) {
+	at := func(sec int) time.Time { return time.Unix(int64(sec), 0) }
+	path := &agent.Path{S: []agent.Step{
+		{Seq: 0, Action: agent.WALK, Start: at(0), End: at(2), To: agent.Point{Y: 2}},
+		{Seq: 1, Action: agent.WAIT, Start: at(2), End: at(3), From: agent.Point{Y: 2}, To: agent.Point{Y: 2}},
+		{Seq: 2, Action: agent.GOTO, Start: at(3), End: at(6), From: agent.Point{Y: 2}, To: agent.Point{X: 3, Y: 2}},
+		{Seq: 3, Action: agent.REPLAY, Start: at(6), End: at(7), From: agent.Point{X: 3, Y: 2}, To: agent.Point{X: 3, Y: 3}},
+	}}
+	path.A = make([]agent.Pace, len(path.S))
+
+	var text bytes.Buffer
+	st := path.Stats()
+	agent.NewTextReporter(&text).Report(agent.Report{Kind: agent.REPORTSTATS, Agent: "agent", Stats: &st})
+	want := "time: 7s (WALK 2s, WAIT 1s, REPLAY 1s, GOTO 3s)"
+	if !strings.Contains(text.String(), want) {
+		t.Errorf("stats lack %q:\n%s", want, text.String())
+	}
+}
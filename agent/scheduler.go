@@ -0,0 +1,314 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrSkipped is reported for a Command that was skipped while it ran.
+var ErrSkipped = errors.New("command skipped")
+
+// Command is a unit of work a Scheduler feeds its agent. It has the shape of cfg.Command, so the commands compiled
+// from a config can be queued as is. ctx carries the agent under AGENT.
+type Command interface {
+	Do(ctx context.Context) error
+}
+
+// CommandFunc adapts a function to a Command.
+type CommandFunc func(ctx context.Context) error
+
+// Do calls f.
+func (f CommandFunc) Do(ctx context.Context) error {
+	return f(ctx)
+}
+
+// State is what a Scheduler is up to.
+type State int
+
+const (
+	IDLE   State = iota // IDLE is a Scheduler with nothing to run.
+	MOVING              // MOVING is a Scheduler running a Command.
+	PAUSED              // PAUSED is a Scheduler holding its agent and its queue.
+	CLOSED              // CLOSED is a Scheduler that no longer runs anything.
+)
+
+// String returns the name of the State.
+func (s State) String() string {
+	switch s {
+	case IDLE:
+		return "IDLE"
+	case MOVING:
+		return "MOVING"
+	case PAUSED:
+		return "PAUSED"
+	case CLOSED:
+		return "CLOSED"
+	}
+	return "state unrecognized"
+}
+
+// StateChange is emitted by a Scheduler whenever its State changes, or a Command ends with an error. Command is the
+// Command that was running, if any, and Err the error it ended with when the change follows its end.
+type StateChange struct {
+	From, To State
+	Command  Command
+	Err      error
+	Time     time.Time
+}
+
+// Scheduler feeds a queue of Commands to an agent, one at a time, and lets the queue be controlled while it runs:
+// paused, resumed, skipped, cleared or jumped by an urgent Command. It is safe for concurrent use.
+type Scheduler struct {
+	agent     Agent
+	ctx       context.Context
+	clock     Clock
+	queue     []Command
+	state     State
+	paused    bool
+	skipped   bool
+	current   Command
+	cancel    context.CancelFunc // cancel cancels the running Command
+	listeners []func(StateChange)
+	pending   []StateChange // pending holds the changes not yet dispatched to the listeners
+	kick      chan struct{}
+	done      chan struct{}
+	m         sync.Mutex
+	c         *sync.Cond
+}
+
+// NewScheduler returns a Scheduler feeding a, and starts it. It runs until ctx is done or it is closed. The clock
+// stamping StateChanges is the one stored in ctx under CLOCKCTX, or the RealClock.
+func NewScheduler(ctx context.Context, a Agent) *Scheduler {
+	s := &Scheduler{
+		agent: a,
+		ctx:   context.WithValue(ctx, AGENT, a),
+		clock: RealClock{},
+		kick:  make(chan struct{}, 1),
+		done:  make(chan struct{}),
+	}
+	s.c = sync.NewCond(&s.m)
+	if clock, err := GetClockFromCtx(ctx); err == nil {
+		s.clock = clock
+	}
+	go s.run()
+	go s.dispatch()
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.Close()
+		case <-s.done:
+		}
+	}()
+	return s
+}
+
+// Enqueue adds cmds at the back of the queue.
+func (s *Scheduler) Enqueue(cmds ...Command) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.queue = append(s.queue, cmds...)
+	s.c.Broadcast()
+}
+
+// Urgent puts cmd at the front of the queue, so it runs as soon as the running Command, if any, ends.
+func (s *Scheduler) Urgent(cmd Command) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.queue = append([]Command{cmd}, s.queue...)
+	s.c.Broadcast()
+}
+
+// Pause holds the agent where it is and stops the queue. If the agent is a Pauser, a movement in progress stops at
+// its next pace; otherwise the running Command carries on and the queue holds once it ends.
+func (s *Scheduler) Pause() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.paused || s.state == CLOSED {
+		return
+	}
+	s.paused = true
+	if p, ok := s.agent.(Pauser); ok {
+		p.Pause()
+	}
+	s.transition(PAUSED, nil)
+}
+
+// Resume undoes Pause.
+func (s *Scheduler) Resume() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if !s.paused || s.state == CLOSED {
+		return
+	}
+	s.paused = false
+	if p, ok := s.agent.(Pauser); ok {
+		p.Resume()
+	}
+	if s.current != nil {
+		s.transition(MOVING, nil)
+	} else {
+		s.transition(IDLE, nil)
+	}
+	s.c.Broadcast()
+}
+
+// Skip cancels the running Command, which ends with ErrSkipped. The queue moves on to the next one.
+func (s *Scheduler) Skip() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if s.cancel != nil {
+		s.skipped = true
+		s.cancel()
+	}
+}
+
+// Clear empties the queue. The running Command, if any, carries on.
+func (s *Scheduler) Clear() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.queue = nil
+	s.c.Broadcast()
+}
+
+// Close cancels the running Command, drops the queue and stops the Scheduler. It blocks until the Scheduler has
+// stopped.
+func (s *Scheduler) Close() {
+	s.m.Lock()
+	if s.state == CLOSED {
+		s.m.Unlock()
+		<-s.done
+		return
+	}
+	s.queue = nil
+	if s.cancel != nil {
+		s.cancel()
+	}
+	if s.paused {
+		if p, ok := s.agent.(Pauser); ok {
+			p.Resume()
+		}
+	}
+	s.transition(CLOSED, nil)
+	s.c.Broadcast()
+	s.m.Unlock()
+	<-s.done
+}
+
+// State returns the current State of the Scheduler.
+func (s *Scheduler) State() State {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return s.state
+}
+
+// Len returns the number of Commands waiting in the queue.
+func (s *Scheduler) Len() int {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return len(s.queue)
+}
+
+// Wait blocks until the queue is empty and no Command runs, or the Scheduler is closed.
+func (s *Scheduler) Wait() {
+	s.m.Lock()
+	defer s.m.Unlock()
+	for s.state != CLOSED && (len(s.queue) > 0 || s.current != nil) {
+		s.c.Wait()
+	}
+}
+
+// OnStateChange registers fn to be called with every StateChange, in order, from a goroutine of the Scheduler.
+// fn may call back into the Scheduler. The last changes, up to CLOSED, may reach fn after Close has returned.
+func (s *Scheduler) OnStateChange(fn func(StateChange)) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// run feeds the queue to the agent until the Scheduler is closed.
+func (s *Scheduler) run() {
+	defer close(s.done)
+	s.m.Lock()
+	defer s.m.Unlock()
+	for {
+		for s.state != CLOSED && (s.paused || len(s.queue) == 0) {
+			s.c.Wait()
+		}
+		if s.state == CLOSED {
+			return
+		}
+		cmd := s.queue[0]
+		s.queue = s.queue[1:]
+		ctx, cancel := context.WithCancel(s.ctx)
+		s.current, s.cancel, s.skipped = cmd, cancel, false
+		s.transition(MOVING, nil)
+		s.m.Unlock()
+
+		err := cmd.Do(ctx)
+		cancel()
+
+		s.m.Lock()
+		if s.skipped {
+			err = ErrSkipped
+		}
+		switch {
+		case s.state == CLOSED:
+		case s.paused:
+			s.transition(PAUSED, err)
+		case len(s.queue) == 0:
+			s.transition(IDLE, err)
+		default:
+			s.transition(MOVING, err)
+		}
+		s.current, s.cancel = nil, nil
+		s.c.Broadcast()
+	}
+}
+
+// transition moves the Scheduler to State to and queues the change for the listeners. It must be called with s.m held.
+func (s *Scheduler) transition(to State, err error) {
+	if to == s.state && err == nil {
+		return
+	}
+	s.pending = append(s.pending, StateChange{
+		From:    s.state,
+		To:      to,
+		Command: s.current,
+		Err:     err,
+		Time:    s.clock.Now(),
+	})
+	s.state = to
+	select {
+	case s.kick <- struct{}{}:
+	default:
+	}
+}
+
+// dispatch hands the pending StateChanges to the listeners until the Scheduler has stopped and all are handed.
+func (s *Scheduler) dispatch() {
+	for {
+		select {
+		case <-s.kick:
+		case <-s.done:
+		}
+		s.m.Lock()
+		pending, listeners := s.pending, append(([]func(StateChange))(nil), s.listeners...)
+		s.pending = nil
+		stopped := s.state == CLOSED
+		s.m.Unlock()
+		for _, sc := range pending {
+			for _, fn := range listeners {
+				fn(sc)
+			}
+		}
+		if stopped {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package agent_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+)
+
+// walkFor returns a Command walking the agent in ctx north for secs seconds.
+func walkFor(secs int) agent.Command {
+	return agent.CommandFunc(func(ctx context.Context) error {
+		a, err := agent.GetAgentFromCtx(ctx)
+		if err != nil {
+			return err
+		}
+		return a.(agent.ContextAgent).WalkContext(ctx, time.Duration(secs)*time.Second, agent.NORTH)
+	})
+}
+
+func TestSchedulerPauseResumeSkip(t *testing.T) {
+	h, vc := newTestHare(t, 1, 1)
+	s := agent.NewScheduler(context.Background(), h)
+	defer s.Close()
+
+	var changes []agent.StateChange
+	closed := make(chan struct{})
+	s.OnStateChange(func(sc agent.StateChange) {
+		changes = append(changes, sc)
+		if sc.To == agent.CLOSED {
+			close(closed)
+		}
+	})
+
+	// paces receives where every pace leaves the Hare, once it is taken
+	paces := make(chan agent.Point, 8)
+	cancel := h.Subscribe(func(e agent.Event) {
+		if e.Type == agent.PACETAKEN {
+			paces <- e.Position
+		}
+	})
+	defer cancel()
+
+	s.Enqueue(walkFor(3), walkFor(2))
+	vc.BlockUntil(1)
+	vc.Advance(time.Second)
+	<-paces
+	vc.BlockUntil(1)
+
+	// the pace under way completes, then the Hare holds: it was paused before the pace ended, so it cannot wait on
+	// the clock again
+	s.Pause()
+	vc.Advance(time.Second)
+	if got := s.State(); got != agent.PAUSED {
+		t.Fatalf("State() = %v, want PAUSED", got)
+	}
+	if got := <-paces; got.Y != 2 {
+		t.Fatalf("the pace under way ended at %v, want Y = 2", got)
+	}
+	if got := vc.Waiters(); got != 0 {
+		t.Fatalf("%d waiters on the clock while paused", got)
+	}
+	if got := h.Position(); got.Y != 2 {
+		t.Fatalf("Position() = %v while paused, want Y = 2", got)
+	}
+
+	s.Urgent(walkFor(1))
+	s.Resume()
+	vc.BlockUntil(1)
+	vc.Advance(time.Second) // third pace of the first walk
+	vc.BlockUntil(1)
+	vc.Advance(time.Second) // the urgent walk
+	vc.BlockUntil(1)
+	s.Skip() // the last walk
+	s.Wait()
+
+	if got := h.Position(); got.Y != 4 {
+		t.Errorf("Position() = %v, want Y = 4", got)
+	}
+	if got := s.State(); got != agent.IDLE {
+		t.Errorf("State() = %v, want IDLE", got)
+	}
+	if got := s.Len(); got != 0 {
+		t.Errorf("Len() = %d, want 0", got)
+	}
+
+	s.Close()
+	<-closed
+	var states []agent.State
+	for _, sc := range changes {
+		states = append(states, sc.To)
+	}
+	want := []agent.State{agent.MOVING, agent.PAUSED, agent.MOVING, agent.IDLE, agent.CLOSED}
+	if len(states) != len(want) {
+		t.Fatalf("state changes %v, want %v", states, want)
+	}
+	for i := range want {
+		if states[i] != want[i] {
+			t.Fatalf("state changes %v, want %v", states, want)
+		}
+	}
+	if !errors.Is(changes[3].Err, agent.ErrSkipped) {
+		t.Errorf("last command ended with %v, want ErrSkipped", changes[3].Err)
+	}
+}
+
+func TestSchedulerClear(t *testing.T) {
+	h, vc := newTestHare(t, 1, 1)
+	vc.Join()
+	defer vc.Leave()
+	s := agent.NewScheduler(context.Background(), h)
+	defer s.Close()
+
+	s.Pause()
+	s.Enqueue(walkFor(1), walkFor(1), walkFor(1))
+	s.Clear()
+	s.Enqueue(walkFor(2))
+	s.Resume()
+	s.Wait()
+
+	if got := h.Position(); got.Y != 2 {
+		t.Errorf("Position() = %v, want Y = 2", got)
+	}
+}
@@ -0,0 +1,208 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Named is implemented by agents that carry a name, such as Hare.
+type Named interface {
+	Name() string
+	SetName(name string)
+}
+
+// Tracked is implemented by agents that expose where they are and where they have been, such as Hare.
+type Tracked interface {
+	Position() Point
+	Positions() []Point
+	Path() *Path
+}
+
+// Work is the job a Simulation runs for one of its agents. ctx carries the agent under AGENT.
+type Work func(ctx context.Context, a Agent) error
+
+// ctxKey is the type of the context keys only the package uses, so that they collide with no key of another package.
+type ctxKey string
+
+// participantCTX is the context key of the VirtualClock the Work of a Simulation takes part in, so that a Hare it
+// moves can leave the clock while it is paused.
+var participantCTX = ctxKey("PARTICIPANTCTX")
+
+// Simulation owns a named registry of agents sharing one Clock, and runs work for them concurrently.
+// It is safe for concurrent use.
+type Simulation struct {
+	agents map[string]Agent
+	names  []string // names in order of registration
+	errs   map[string]error
+	clock  Clock
+	m      sync.Mutex
+}
+
+// NewSimulation returns an empty Simulation. Its agents share the Clock stored in ctx under CLOCKCTX, or the
+// RealClock if there is none.
+func NewSimulation(ctx context.Context) *Simulation {
+	s := &Simulation{
+		agents: make(map[string]Agent),
+		errs:   make(map[string]error),
+		clock:  RealClock{},
+	}
+	if clock, err := GetClockFromCtx(ctx); err == nil {
+		s.clock = clock
+	}
+	return s
+}
+
+// Clock returns the Clock shared by the agents of the Simulation.
+func (s *Simulation) Clock() Clock {
+	return s.clock
+}
+
+// Register adds the agent under name. Agents that are Named are renamed to match.
+func (s *Simulation) Register(name string, a Agent) error {
+	s.m.Lock()
+	defer s.m.Unlock()
+	if name == "" {
+		return fmt.Errorf("agent name is empty")
+	}
+	if _, ok := s.agents[name]; ok {
+		return fmt.Errorf("agent %q already registered", name)
+	}
+	if n, ok := a.(Named); ok {
+		n.SetName(name)
+	}
+	s.agents[name] = a
+	s.names = append(s.names, name)
+	return nil
+}
+
+// Agent returns the agent registered under name.
+func (s *Simulation) Agent(name string) (Agent, error) {
+	s.m.Lock()
+	defer s.m.Unlock()
+	a, ok := s.agents[name]
+	if !ok {
+		return nil, fmt.Errorf("agent %q not registered", name)
+	}
+	return a, nil
+}
+
+// Names returns the names of the registered agents, in order of registration.
+func (s *Simulation) Names() []string {
+	s.m.Lock()
+	defer s.m.Unlock()
+	return append([]string(nil), s.names...)
+}
+
+// Run runs the work of every named agent concurrently and waits for all of it to finish. One agent failing does not
+// stop the others; cancelling ctx stops them all. The returned error joins the error of every agent that failed.
+//
+// When the shared Clock is a VirtualClock, every agent joins it before any starts, so the clock only moves once
+// all of them are waiting on it and their timelines stay in step.
+func (s *Simulation) Run(ctx context.Context, work map[string]Work) error {
+	type job struct {
+		name string
+		a    Agent
+		w    Work
+	}
+	for name := range work {
+		if _, err := s.Agent(name); err != nil {
+			return err
+		}
+	}
+	var jobs []job
+	for _, name := range s.Names() {
+		w, ok := work[name]
+		if !ok {
+			continue
+		}
+		a, _ := s.Agent(name)
+		jobs = append(jobs, job{name: name, a: a, w: w})
+	}
+
+	vc, virtual := s.clock.(*VirtualClock)
+	if virtual {
+		for range jobs {
+			vc.Join()
+		}
+	}
+	var wg sync.WaitGroup
+	for _, j := range jobs {
+		j := j
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if virtual {
+				defer vc.Leave()
+			}
+			ctx := context.WithValue(ctx, AGENT, j.a)
+			if virtual {
+				ctx = context.WithValue(ctx, participantCTX, vc)
+			}
+			err := j.w(ctx, j.a)
+			s.m.Lock()
+			s.errs[j.name] = err
+			s.m.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	var errs []error
+	s.m.Lock()
+	defer s.m.Unlock()
+	for _, j := range jobs {
+		if err := s.errs[j.name]; err != nil {
+			errs = append(errs, fmt.Errorf("agent %s: %w", j.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AgentReport is where one agent of a Simulation ended up, and how it got there.
+type AgentReport struct {
+	Name      string
+	Position  Point
+	Positions []Point
+	Path      *Path
+	Err       error
+}
+
+// SimulationReport gathers the AgentReport of every agent, along with their paths combined into one.
+type SimulationReport struct {
+	Agents   []AgentReport
+	Combined *Path
+}
+
+// Report returns the paths of the agents as they stand, in order of registration. Agents that are not Tracked
+// are reported with an empty Path.
+func (s *Simulation) Report() *SimulationReport {
+	r := &SimulationReport{Combined: &Path{}}
+	for _, name := range s.Names() {
+		a, _ := s.Agent(name)
+		s.m.Lock()
+		ar := AgentReport{Name: name, Path: &Path{}, Err: s.errs[name]}
+		s.m.Unlock()
+		if t, ok := a.(Tracked); ok {
+			ar.Position, ar.Positions, ar.Path = t.Position(), t.Positions(), t.Path()
+		}
+		r.Combined.M = append(r.Combined.M, ar.Path.M...)
+		r.Combined.A = append(r.Combined.A, ar.Path.A...)
+		r.Combined.S = append(r.Combined.S, ar.Path.S...)
+		r.Agents = append(r.Agents, ar)
+	}
+	return r
+}
+
+// Println prints the Path and final position of every agent, then the number of paces taken altogether.
+func (r *SimulationReport) Println() {
+	r.ReportTo(defaultReporter)
+}
+
+// ReportTo hands the Path and final position of every agent to rep, then the Path of all of them combined.
+func (r *SimulationReport) ReportTo(rep Reporter) {
+	for _, ar := range r.Agents {
+		rep.Report(Report{Kind: REPORTSUMMARY, Agent: ar.Name, Path: ar.Path, Positions: ar.Positions, At: ar.Position, Err: ar.Err})
+	}
+	rep.Report(Report{Kind: REPORTSUMMARY, Path: r.Combined, Agents: len(r.Agents)})
+}
@@ -0,0 +1,144 @@
+package agent
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Stats summarizes a Path.
+type Stats struct {
+	Paces            int
+	Distance         float64                   // Distance is the length of the Path, pace after pace.
+	Displacement     Point                     // Displacement is where the Path ends relative to where it starts.
+	NetDistance      float64                   // NetDistance is the straight-line length of the Displacement.
+	Bearing          float64                   // Bearing is the compass bearing of the Displacement in degrees, clockwise from north; 0 when there is none.
+	Bounds           Bounds                    // Bounds is the smallest rectangle holding every position along the Path.
+	Elapsed          time.Duration             // Elapsed is the time from the start of the first Step to the end of the last.
+	TimeByAction     map[MovType]time.Duration // TimeByAction is the time the Steps of each MovType took.
+	AverageSpeed     float64                   // AverageSpeed is the Distance covered per second Elapsed.
+	MaxSpeed         float64                   // MaxSpeed is the fastest a single pace covered ground, per second.
+	DirectionChanges int                       // DirectionChanges counts the paces heading another way than the pace that moved before them.
+	TurningPoints    []Point                   // TurningPoints are where each change of direction happened.
+}
+
+// Stats returns the Stats of the Path. Paths without Steps, such as the one of Point.Path, are taken to start at the
+// origin and to take no time. The Path must be the Path of a single agent.
+func (p *Path) Stats() Stats {
+	steps := p.steps()
+	st := Stats{Paces: len(steps), TimeByAction: make(map[MovType]time.Duration)}
+	if len(steps) == 0 {
+		return st
+	}
+
+	first, last := steps[0], steps[len(steps)-1]
+	st.Bounds = Bounds{Min: Point{X: first.From.X, Y: first.From.Y}, Max: Point{X: first.From.X, Y: first.From.Y}}
+	st.Displacement = Point{X: last.To.X - first.From.X, Y: last.To.Y - first.From.Y}
+	st.NetDistance = first.From.Distance(&last.To)
+	if st.Displacement.X != 0 || st.Displacement.Y != 0 {
+		// north is +Y and east is -X, following the axes Pace.ScalarMove moves along
+		st.Bearing = math.Mod(math.Atan2(float64(-st.Displacement.X), float64(st.Displacement.Y))*180/math.Pi+360, 360)
+	}
+	st.Elapsed = last.End.Sub(first.Start)
+
+	heading := Direction(-1)
+	for _, s := range steps {
+		length := s.From.Distance(&s.To)
+		st.Distance += length
+		st.TimeByAction[s.Action] += s.Duration()
+		if d := s.Duration(); d > 0 && length/d.Seconds() > st.MaxSpeed {
+			st.MaxSpeed = length / d.Seconds()
+		}
+		st.Bounds.Min.X, st.Bounds.Max.X = spread(st.Bounds.Min.X, st.Bounds.Max.X, s.To.X)
+		st.Bounds.Min.Y, st.Bounds.Max.Y = spread(st.Bounds.Min.Y, st.Bounds.Max.Y, s.To.Y)
+
+		d, moved := headingOf(s.To.X-s.From.X, s.To.Y-s.From.Y)
+		if !moved {
+			continue
+		}
+		if heading != Direction(-1) && d != heading {
+			st.DirectionChanges++
+			st.TurningPoints = append(st.TurningPoints, Point{X: s.From.X, Y: s.From.Y})
+		}
+		heading = d
+	}
+	if st.Elapsed > 0 {
+		st.AverageSpeed = st.Distance / st.Elapsed.Seconds()
+	}
+	return st
+}
+
+// steps returns the Steps of the Path. A Path without Steps gets ones that start at the origin, take no time and
+// are numbered in order.
+func (p *Path) steps() []Step {
+	if len(p.S) == len(p.A) {
+		return p.S
+	}
+	steps := make([]Step, 0, len(p.A))
+	var at Point
+	for i, pace := range p.A {
+		to := Point{X: at.X + pace.x, Y: at.Y + pace.y}
+		steps = append(steps, Step{Seq: i, Pace: pace, From: at, To: to})
+		at = to
+	}
+	return steps
+}
+
+// spread widens the range from min to max so it holds c.
+func spread(min, max, c Coordinate) (Coordinate, Coordinate) {
+	if c < min {
+		min = c
+	}
+	if c > max {
+		max = c
+	}
+	return min, max
+}
+
+// Stats returns the Stats of the Path traveled thus far.
+func (h *Hare) Stats() Stats {
+	return h.Path().Stats()
+}
+
+// ReportStats hands the Stats of the Path of every agent to rep, in order of registration.
+func (r *SimulationReport) ReportStats(rep Reporter) {
+	for _, ar := range r.Agents {
+		st := ar.Path.Stats()
+		rep.Report(Report{Kind: REPORTSTATS, Agent: ar.Name, Path: ar.Path, At: ar.Position, Stats: &st})
+	}
+}
+
+// fprintStats writes the Stats of the agent named name to w.
+func fprintStats(w io.Writer, name string, st *Stats) {
+	if st == nil {
+		return
+	}
+	fprintln(w, 0, "STATS %s", name)
+	fprintln(w, 0, "\tpaces: %d", st.Paces)
+	fprintln(w, 0, "\tdistance: %.2f", st.Distance)
+	fprintln(w, 0, "\tdisplacement: (%d, %d), %.2f at bearing %.1f", st.Displacement.X, st.Displacement.Y, st.NetDistance, st.Bearing)
+	fprintln(w, 0, "\tbounds: (%d, %d) to (%d, %d)", st.Bounds.Min.X, st.Bounds.Min.Y, st.Bounds.Max.X, st.Bounds.Max.Y)
+	actions := make([]MovType, 0, len(st.TimeByAction))
+	for action := range st.TimeByAction {
+		actions = append(actions, action)
+	}
+	sort.Slice(actions, func(i, j int) bool { return actions[i] < actions[j] })
+	var spent []string
+	for _, action := range actions {
+		spent = append(spent, fmt.Sprintf("%v %v", action, st.TimeByAction[action]))
+	}
+	fprintln(w, 0, "\ttime: %v (%s)", st.Elapsed, strings.Join(spent, ", "))
+	fprintln(w, 0, "\tspeed: average %.2f, max %.2f", st.AverageSpeed, st.MaxSpeed)
+	turns := fmt.Sprintf("\tdirection changes: %d", st.DirectionChanges)
+	for i, p := range st.TurningPoints {
+		sep := ","
+		if i == 0 {
+			sep = " at"
+		}
+		turns += fmt.Sprintf("%s (%d, %d)", sep, p.X, p.Y)
+	}
+	fprintln(w, 0, "%s", turns)
+}
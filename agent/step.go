@@ -0,0 +1,36 @@
+package agent
+
+import (
+	"time"
+)
+
+// Step is a Pace as it was recorded in the Path of an agent: when it was taken, by which movement, at what Speed,
+// and where it took the agent from and to.
+type Step struct {
+	Seq        int       // Seq numbers the Steps of an agent in the order they were recorded, from 0.
+	Movement   int       // Movement is the ID of the Handle of the movement that took the Step, 0 for a Step recorded by hand.
+	Start, End time.Time // Start and End are the times on the Clock of the agent the Step began and ended at.
+	Action     MovType
+	Speed      Speed
+	Pace       Pace
+	From, To   Point
+}
+
+// Duration returns how long the Step took.
+func (s Step) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// recordStep records st into the Path traveled thus far, numbering it. It must be called with h.m held.
+func (h *Hare) recordStep(st Step) Step {
+	st.Seq = len(h.pathTaken.S)
+	h.rMap(&st.Pace)
+	h.rArr(&st.Pace)
+	h.rSteps(st)
+	return st
+}
+
+// rSteps stores the Step in the *Path.S in the Hare struct
+func (h *Hare) rSteps(st Step) {
+	h.pathTaken.S = append(h.pathTaken.S, st)
+}
@@ -0,0 +1,123 @@
+package agent
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Vector is a position in the plane that, unlike a Point, may fall between Coordinates, such as the position of an
+// agent halfway through a pace.
+type Vector struct {
+	X, Y float64
+}
+
+// Vector returns the Point as a Vector.
+func (p Point) Vector() Vector {
+	return Vector{X: float64(p.X), Y: float64(p.Y)}
+}
+
+// Point returns the Point nearest to the Vector.
+func (v Vector) Point() Point {
+	return Point{X: Coordinate(math.Round(v.X)), Y: Coordinate(math.Round(v.Y))}
+}
+
+// Segment is the part of a Path taken by one movement.
+type Segment struct {
+	Movement   int // Movement is the ID of the Handle of the movement.
+	Action     MovType
+	Start, End time.Time
+	From, To   Point
+	Steps      []Step
+}
+
+// at returns where the Step had the agent at t, moving it at a constant rate from From to To between Start and End.
+func (s Step) at(t time.Time) Vector {
+	d := s.Duration()
+	if d <= 0 || !t.Before(s.End) {
+		return s.To.Vector()
+	}
+	if !t.After(s.Start) {
+		return s.From.Vector()
+	}
+	frac := float64(t.Sub(s.Start)) / float64(d)
+	return Vector{
+		X: float64(s.From.X) + frac*float64(s.To.X-s.From.X),
+		Y: float64(s.From.Y) + frac*float64(s.To.Y-s.From.Y),
+	}
+}
+
+// PositionAt returns where the Path had its agent at t, interpolating within the Step running at t. Before the
+// first Step the agent is where that Step starts; between Steps and after the last one, it is where the previous
+// Step left it. It reports false for a Path without Steps. The Path must be the Path of a single agent.
+func (p *Path) PositionAt(t time.Time) (Vector, bool) {
+	if len(p.S) == 0 {
+		return Vector{}, false
+	}
+	i := sort.Search(len(p.S), func(i int) bool {
+		return p.S[i].Start.After(t)
+	})
+	if i == 0 {
+		return p.S[0].From.Vector(), true
+	}
+	return p.S[i-1].at(t), true
+}
+
+// Segments returns the Path split into the Segments of the movements that took it, in order. Steps recorded by
+// hand belong to no Segment.
+func (p *Path) Segments() []Segment {
+	var segs []Segment
+	for _, st := range p.S {
+		if st.Movement == 0 {
+			continue
+		}
+		if n := len(segs); n > 0 && segs[n-1].Movement == st.Movement {
+			segs[n-1].End, segs[n-1].To = st.End, st.To
+			segs[n-1].Steps = append(segs[n-1].Steps, st)
+			continue
+		}
+		segs = append(segs, Segment{
+			Movement: st.Movement,
+			Action:   st.Action,
+			Start:    st.Start,
+			End:      st.End,
+			From:     st.From,
+			To:       st.To,
+			Steps:    []Step{st},
+		})
+	}
+	return segs
+}
+
+// SegmentAt returns the Segment of the movement running at t: the one that started at or before t and had not
+// ended yet, or an instantaneous one taken at t. It reports false when no movement was running at t.
+func (p *Path) SegmentAt(t time.Time) (Segment, bool) {
+	var found Segment
+	var ok bool
+	for _, seg := range p.Segments() {
+		if seg.Start.After(t) {
+			break
+		}
+		if t.Before(seg.End) || (seg.Start.Equal(t) && seg.End.Equal(t)) {
+			found, ok = seg, true
+		}
+	}
+	return found, ok
+}
+
+// PositionAt returns where the Hare was at t, interpolated within the pace it was taking then. Before its first
+// pace it is where that pace started, and after its last one where that pace left it. A Hare that took no pace is
+// where it stands.
+func (h *Hare) PositionAt(t time.Time) Vector {
+	h.m.Lock()
+	defer h.m.Unlock()
+	if v, ok := h.pathTaken.PositionAt(t); ok {
+		return v
+	}
+	return h.pos.Vector()
+}
+
+// SegmentAt returns the Segment of the movement the Hare was carrying out at t, if it was carrying out any.
+func (h *Hare) SegmentAt(t time.Time) (Segment, bool) {
+	return h.Path().SegmentAt(t)
+}
@@ -0,0 +1,42 @@
+package agent
+
+import (
+	"context"
+	"time"
+)
+
+// Tortoise is slow but steady: it never goes faster than it walks, so asking it to run makes it walk.
+type Tortoise struct {
+	*Hare
+}
+
+// NewTortoise returns a Tortoise that walks at walk Speed. opts are applied as by NewHare; a speed profile it is
+// given only sets its walk.
+func NewTortoise(ctx context.Context, walk Speed, opts ...Opts) (*Tortoise, error) {
+	h, err := NewHare(ctx, walk, walk, opts...)
+	if err != nil {
+		return nil, err
+	}
+	h.nature.run = h.nature.walk
+	return &Tortoise{Hare: h}, nil
+}
+
+// Run walks, since a Tortoise does not run.
+func (t *Tortoise) Run(duration time.Duration, dir Direction) error {
+	return t.Walk(duration, dir)
+}
+
+// RunContext walks, since a Tortoise does not run.
+func (t *Tortoise) RunContext(ctx context.Context, duration time.Duration, dir Direction) error {
+	return t.WalkContext(ctx, duration, dir)
+}
+
+// Stride walks for one second.
+func (t *Tortoise) Stride(ctx context.Context, dir Direction) error {
+	return t.WalkContext(ctx, time.Second, dir)
+}
+
+// RunAsync queues a walk, since a Tortoise does not run.
+func (t *Tortoise) RunAsync(ctx context.Context, duration time.Duration, dir Direction) *Handle {
+	return t.WalkAsync(ctx, duration, dir)
+}
@@ -2,7 +2,6 @@ package agent
 
 import (
 	"fmt"
-	"github.com/dark-enstein/chardot/internal/ilog"
 )
 
 // MovType defines the type of action being carried out on an Agent
@@ -21,6 +20,12 @@ func (m MovType) String() string {
 		return fmt.Sprintf("TOTAL")
 	case ORIGIN:
 		return fmt.Sprintf("ORIGIN\n")
+	case WAIT:
+		return fmt.Sprintf("WAIT")
+	case REPLAY:
+		return fmt.Sprintf("REPLAY")
+	case GOTO:
+		return fmt.Sprintf("GOTO")
 	}
 	return "action unrecognized"
 }
@@ -42,6 +47,7 @@ const (
 	SOUTH
 	EAST
 	WEST
+	STILL // STILL is the Direction of A Pace that does not move, recorded while an Agent waits.
 )
 
 func (d Direction) String() string {
@@ -74,8 +80,78 @@ func (d Direction) String() string {
 		return "EAST"
 	case WEST:
 		return "WEST"
-	default:
-		Clog.Log(ilog.PANIC, "direction %v unrecognized", d)
+	case STILL:
+		return "STILL"
 	}
-	return ""
+	return fmt.Sprintf("Direction(%d)", int(d))
+}
+
+// Validate returns an error wrapping ErrUnknownDirection if d is not one of the Directions of the package.
+func (d Direction) Validate() error {
+	if d < FORWARD || d > STILL {
+		return fmt.Errorf("%w: %v", ErrUnknownDirection, d)
+	}
+	return nil
+}
+
+// IsDiagonal reports whether the Direction is one of the intercardinal Directions.
+func (d Direction) IsDiagonal() bool {
+	switch d {
+	case NORTHEAST, NORTHWEST, SOUTHEAST, SOUTHWEST:
+		return true
+	}
+	return false
+}
+
+// Unit returns the displacement of one unit toward d: along both axes for an intercardinal Direction, and none for
+// STILL or a Direction that does not move. It is what a Pace toward d covers at a Speed of 1.
+func (d Direction) Unit() (x, y Coordinate) {
+	ns, ew := d.Split()
+	switch ns {
+	case FORWARD, NORTH:
+		y = 1
+	case BACKWARD, SOUTH:
+		y = -1
+	}
+	switch ew {
+	case RIGHT, WEST:
+		x = 1
+	case LEFT, EAST:
+		x = -1
+	}
+	return x, y
+}
+
+// Split returns the north/south and east/west components of an intercardinal Direction. Any other Direction is
+// returned as is in the component matching its axis, with -1 in the other.
+func (d Direction) Split() (ns, ew Direction) {
+	switch d {
+	case NORTHEAST:
+		return NORTH, EAST
+	case NORTHWEST:
+		return NORTH, WEST
+	case SOUTHEAST:
+		return SOUTH, EAST
+	case SOUTHWEST:
+		return SOUTH, WEST
+	case FORWARD, BACKWARD, YDIRECTION, NORTH, SOUTH:
+		return d, Direction(-1)
+	}
+	return Direction(-1), d
+}
+
+// Intercardinal returns the intercardinal Direction made of the north/south component ns and the east/west component ew.
+// It reports false when the components do not form one.
+func Intercardinal(ns, ew Direction) (Direction, bool) {
+	switch {
+	case (ns == NORTH || ns == FORWARD) && (ew == EAST || ew == LEFT):
+		return NORTHEAST, true
+	case (ns == NORTH || ns == FORWARD) && (ew == WEST || ew == RIGHT):
+		return NORTHWEST, true
+	case (ns == SOUTH || ns == BACKWARD) && (ew == EAST || ew == LEFT):
+		return SOUTHEAST, true
+	case (ns == SOUTH || ns == BACKWARD) && (ew == WEST || ew == RIGHT):
+		return SOUTHWEST, true
+	}
+	return Direction(-1), false
 }
@@ -20,3 +20,12 @@ func GetAgentFromCtx(ctx context.Context) (Agent, error) {
 	}
 	return agent, nil
 }
+
+// GetClockFromCtx returns the Clock stored in the context under CLOCKCTX.
+func GetClockFromCtx(ctx context.Context) (Clock, error) {
+	clock, ok := ctx.Value(CLOCKCTX).(Clock)
+	if !ok {
+		return nil, fmt.Errorf("clock not found in context")
+	}
+	return clock, nil
+}
@@ -0,0 +1,301 @@
+package agent
+
+import (
+	"bufio"
+	"container/heap"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+var WORLDCTX = "WORLDCTX" // WORLDCTX is the context key under which the World agents move over is stored.
+
+// GetWorldFromCtx returns the World stored in the context under WORLDCTX.
+func GetWorldFromCtx(ctx context.Context) (*World, error) {
+	w, ok := ctx.Value(WORLDCTX).(*World)
+	if !ok || w == nil {
+		return nil, fmt.Errorf("world not found in context")
+	}
+	return w, nil
+}
+
+// World is a grid of cells agents move over: the cells within its Bounds, some of them blocked by obstacles. A
+// World is not safe for concurrent use while it is being changed with Block; agents only ever read it.
+type World struct {
+	Bounds    Bounds
+	Diagonals bool // Diagonals lets routes take intercardinal steps. Without it, routes only go along the axes.
+	blocked   map[cell]bool
+}
+
+// cell is a Point as a map key.
+type cell struct {
+	x, y Coordinate
+}
+
+func cellOf(p Point) cell {
+	return cell{x: p.X, y: p.Y}
+}
+
+func (c cell) point() Point {
+	return Point{X: c.x, Y: c.y}
+}
+
+// NewWorld returns a World within b, with the blocked cells, that routes may cross diagonally.
+func NewWorld(b Bounds, blocked ...Point) (*World, error) {
+	if b.Min.X > b.Max.X || b.Min.Y > b.Max.Y {
+		return nil, fmt.Errorf("bounds %v to %v are empty", b.Min, b.Max)
+	}
+	w := &World{
+		Bounds:    Bounds{Min: Point{X: b.Min.X, Y: b.Min.Y}, Max: Point{X: b.Max.X, Y: b.Max.Y}},
+		Diagonals: true,
+		blocked:   make(map[cell]bool),
+	}
+	for _, p := range blocked {
+		w.Block(p)
+	}
+	return w, nil
+}
+
+// Block puts an obstacle on p.
+func (w *World) Block(p Point) {
+	w.blocked[cellOf(p)] = true
+}
+
+// Blocked reports whether there is an obstacle on p.
+func (w *World) Blocked(p Point) bool {
+	return w.blocked[cellOf(p)]
+}
+
+// Free reports whether an agent may stand on p: it is within the Bounds of the World, and not blocked.
+func (w *World) Free(p Point) bool {
+	return w.Bounds.Contains(p) && !w.Blocked(p)
+}
+
+// passable reports whether an agent may take a step of one cell, along an axis or diagonally, from p to q. A
+// diagonal step may not cut the corner of an obstacle.
+func (w *World) passable(p, q Point) bool {
+	if !w.Free(q) {
+		return false
+	}
+	if p.X != q.X && p.Y != q.Y {
+		return w.Free(Point{X: q.X, Y: p.Y}) && w.Free(Point{X: p.X, Y: q.Y})
+	}
+	return true
+}
+
+// check returns the error an agent on p gets for taking pace: ErrOutOfBounds if it leaves the World, ErrBlocked if
+// it enters or cuts the corner of an obstacle on the way, cell after cell, or nil.
+func (w *World) check(p Point, pace *Pace) error {
+	x, y := pace.x.abs(), pace.y.abs()
+	n := x
+	if y > n {
+		n = y
+	}
+	at := p
+	for i := Coordinate(1); i <= n; i++ {
+		dx, dy := i, i
+		if dx > x {
+			dx = x
+		}
+		if dy > y {
+			dy = y
+		}
+		next := Point{X: p.X + sign(pace.x)*dx, Y: p.Y + sign(pace.y)*dy}
+		if !w.Bounds.Contains(next) {
+			return ErrOutOfBounds
+		}
+		if !w.passable(at, next) {
+			return ErrBlocked
+		}
+		at = next
+	}
+	return nil
+}
+
+// CheckMove returns the error an agent on from gets for a pace that takes it straight to to, as a Hare in the World
+// would: ErrOutOfBounds if it leaves the World, ErrBlocked if it enters or cuts the corner of an obstacle, or nil.
+func (w *World) CheckMove(from, to Point) error {
+	return w.check(from, paceBetween(from, to))
+}
+
+// LoadWorld reads a World from an ASCII map, one row of cells per line, north at the top. A '#' is an obstacle,
+// and any other character a free cell; '@' marks the origin. Agents move west along +X, so the columns of the map
+// count down X from left to right. Without an '@', the origin is the bottom right cell, the south-east corner of
+// the map. Lines may have different lengths; the World spans the longest, and blank lines at the end are ignored.
+// Routes in the World may cross diagonally.
+func LoadWorld(r io.Reader) (*World, error) {
+	var rows []string
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		rows = append(rows, strings.TrimRight(sc.Text(), "\r"))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	for len(rows) > 0 && strings.TrimSpace(rows[len(rows)-1]) == "" {
+		rows = rows[:len(rows)-1]
+	}
+	width := 0
+	for _, row := range rows {
+		if len(row) > width {
+			width = len(row)
+		}
+	}
+	if width == 0 {
+		return nil, fmt.Errorf("map is empty")
+	}
+	height := len(rows)
+
+	// origin is the column and row of the origin, counted from the bottom right cell
+	origin, found := cell{}, false
+	for r, row := range rows {
+		if c := strings.IndexByte(row, '@'); c >= 0 {
+			if found {
+				return nil, fmt.Errorf("map line %d: more than one '@'", r+1)
+			}
+			origin, found = cell{x: Coordinate(width - 1 - c), y: Coordinate(height - 1 - r)}, true
+		}
+	}
+	w, err := NewWorld(Bounds{
+		Min: Point{X: -origin.x, Y: -origin.y},
+		Max: Point{X: Coordinate(width-1) - origin.x, Y: Coordinate(height-1) - origin.y},
+	})
+	if err != nil {
+		return nil, err
+	}
+	for r, row := range rows {
+		for c := 0; c < len(row); c++ {
+			if row[c] == '#' {
+				w.Block(Point{X: Coordinate(width-1-c) - origin.x, Y: Coordinate(height-1-r) - origin.y})
+			}
+		}
+	}
+	return w, nil
+}
+
+// moves returns the steps of one cell a route may take: along the axes, then diagonally if the World allows it.
+func (w *World) moves() []cell {
+	axes := []cell{{0, 1}, {0, -1}, {1, 0}, {-1, 0}}
+	if !w.Diagonals {
+		return axes
+	}
+	return append(axes, cell{1, 1}, cell{1, -1}, cell{-1, 1}, cell{-1, -1})
+}
+
+// Route returns the shortest route from from to to that stays in the World and clear of its obstacles, as every
+// cell it goes through after from, found with A*. Steps along an axis are 1 long, and diagonal ones, when the
+// World allows them, √2. It returns an error wrapping ErrBlocked if to is not free, or ErrNoRoute if no route leads
+// there.
+func (w *World) Route(from, to Point) ([]Point, error) {
+	if !w.Free(to) {
+		return nil, fmt.Errorf("%w: (%d, %d) is not free", ErrBlocked, to.X, to.Y)
+	}
+	start, goal := cellOf(from), cellOf(to)
+	// octile distance, or Manhattan distance without diagonals, never overestimates what is left
+	estimate := func(c cell) float64 {
+		dx, dy := float64((c.x - goal.x).abs()), float64((c.y - goal.y).abs())
+		if !w.Diagonals {
+			return dx + dy
+		}
+		return math.Max(dx, dy) + (math.Sqrt2-1)*math.Min(dx, dy)
+	}
+	cost := map[cell]float64{start: 0}
+	prev := map[cell]cell{}
+	open := &routeQueue{}
+	heap.Push(open, &routeNode{at: start, score: estimate(start)})
+	done := map[cell]bool{}
+	for open.Len() > 0 {
+		n := heap.Pop(open).(*routeNode)
+		if done[n.at] {
+			continue
+		}
+		if n.at == goal {
+			var route []Point
+			for c := goal; c != start; c = prev[c] {
+				route = append(route, c.point())
+			}
+			for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+				route[i], route[j] = route[j], route[i]
+			}
+			return route, nil
+		}
+		done[n.at] = true
+		for _, m := range w.moves() {
+			next := cell{x: n.at.x + m.x, y: n.at.y + m.y}
+			if done[next] || !w.passable(n.at.point(), next.point()) {
+				continue
+			}
+			step := 1.0
+			if m.x != 0 && m.y != 0 {
+				step = math.Sqrt2
+			}
+			if c, ok := cost[next]; ok && c <= cost[n.at]+step {
+				continue
+			}
+			cost[next], prev[next] = cost[n.at]+step, n.at
+			heap.Push(open, &routeNode{at: next, score: cost[next] + estimate(next)})
+		}
+	}
+	return nil, fmt.Errorf("%w: from (%d, %d) to (%d, %d)", ErrNoRoute, from.X, from.Y, to.X, to.Y)
+}
+
+// PlanLegs returns the legs of the Route from from to to, every run of steps in the same Direction making one Leg.
+func (w *World) PlanLegs(from, to Point) ([]Leg, error) {
+	route, err := w.Route(from, to)
+	if err != nil {
+		return nil, err
+	}
+	var legs []Leg
+	at := Point{X: from.X, Y: from.Y}
+	for _, next := range route {
+		d, _ := headingOf(next.X-at.X, next.Y-at.Y)
+		if n := len(legs); n > 0 && legs[n-1].Direction == d {
+			legs[n-1].Distance++
+			legs[n-1].To = next
+		} else {
+			legs = append(legs, Leg{Direction: d, Distance: 1, From: at, To: next})
+		}
+		at = next
+	}
+	return legs, nil
+}
+
+// routeNode is a cell waiting to be explored by Route, scored by the cost of reaching it plus the estimate of what
+// is left. seq breaks ties in the order cells were queued, so routes come out the same every time.
+type routeNode struct {
+	at    cell
+	score float64
+	seq   int
+}
+
+// routeQueue is the priority queue of Route, lowest score first.
+type routeQueue struct {
+	nodes  []*routeNode
+	pushed int
+}
+
+func (q *routeQueue) Len() int { return len(q.nodes) }
+
+func (q *routeQueue) Less(i, j int) bool {
+	if q.nodes[i].score != q.nodes[j].score {
+		return q.nodes[i].score < q.nodes[j].score
+	}
+	return q.nodes[i].seq < q.nodes[j].seq
+}
+
+func (q *routeQueue) Swap(i, j int) { q.nodes[i], q.nodes[j] = q.nodes[j], q.nodes[i] }
+
+func (q *routeQueue) Push(x interface{}) {
+	n := x.(*routeNode)
+	n.seq = q.pushed
+	q.pushed++
+	q.nodes = append(q.nodes, n)
+}
+
+func (q *routeQueue) Pop() interface{} {
+	n := q.nodes[len(q.nodes)-1]
+	q.nodes = q.nodes[:len(q.nodes)-1]
+	return n
+}
@@ -0,0 +1,90 @@
+package agent_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+)
+
+// MAP has its origin at the bottom right and a wall at X = 5, from Y = 1 up to Y = 4.
+const MAP = `
+..........
+....#.....
+....#.....
+....#.....
+....#.....
+.........@
+`
+
+func TestWorld(t *testing.T) {
+	w, err := agent.LoadWorld(strings.NewReader(strings.TrimPrefix(MAP, "\n")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (agent.Bounds{Max: agent.Point{X: 9, Y: 5}}); w.Bounds != want {
+		t.Errorf("Bounds = %v, want %v", w.Bounds, want)
+	}
+	if !w.Blocked(agent.Point{X: 5, Y: 1}) || w.Blocked(agent.Point{X: 5, Y: 0}) || w.Free(agent.Point{X: 10}) {
+		t.Errorf("LoadWorld() put the obstacles or the bounds in the wrong place")
+	}
+
+	// around the bottom of the wall, not cutting its corner
+	route, err := w.Route(agent.Point{}, agent.Point{X: 8, Y: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(route) != 10 || route[5] != (agent.Point{X: 6}) || route[9] != (agent.Point{X: 8, Y: 4}) {
+		t.Errorf("Route() = %v", route)
+	}
+	w.Diagonals = false
+	if route, err := w.Route(agent.Point{}, agent.Point{X: 8, Y: 4}); err != nil || len(route) != 12 {
+		t.Errorf("Route() without diagonals = %v, %v, want 12 cells", route, err)
+	}
+	w.Diagonals = true
+	if _, err := w.Route(agent.Point{}, agent.Point{X: 5, Y: 2}); !errors.Is(err, agent.ErrBlocked) {
+		t.Errorf("Route() onto the wall = %v, want %v", err, agent.ErrBlocked)
+	}
+
+	h, vc := newTestHare(t, 2, 2, agent.WithWorld(w))
+	vc.Join()
+	defer vc.Leave()
+	if err := h.MoveTo(agent.Point{X: 8, Y: 4}); err != nil {
+		t.Fatal(err)
+	}
+	for _, st := range h.Path().S {
+		if w.Blocked(st.To) {
+			t.Errorf("MoveTo() went through the obstacle at %v", st.To)
+		}
+	}
+	if got := h.Position(); got != (agent.Point{X: 8, Y: 4}) {
+		t.Errorf("Position() = %v after MoveTo, want (8, 4)", got)
+	}
+
+	// walking into the wall stops short of it
+	err = h.Walk(3*time.Second, agent.EAST)
+	var pme *agent.PartialMoveError
+	if !errors.As(err, &pme) || !errors.Is(err, agent.ErrBlocked) || pme.At != (agent.Point{X: 6, Y: 4}) {
+		t.Errorf("Walk() into the wall = %v, want it stopped at (6, 4) with %v", err, agent.ErrBlocked)
+	}
+
+	if err := w.CheckMove(agent.Point{X: 6, Y: 4}, agent.Point{X: 4, Y: 4}); !errors.Is(err, agent.ErrBlocked) {
+		t.Errorf("CheckMove() across the wall = %v, want %v", err, agent.ErrBlocked)
+	}
+	if err := w.CheckMove(agent.Point{}, agent.Point{X: -1}); !errors.Is(err, agent.ErrOutOfBounds) {
+		t.Errorf("CheckMove() off the map = %v, want %v", err, agent.ErrOutOfBounds)
+	}
+	if x, y := agent.SOUTHWEST.Unit(); x != 1 || y != -1 {
+		t.Errorf("SOUTHWEST.Unit() = %d, %d, want 1, -1", x, y)
+	}
+
+	walled, err := agent.NewWorld(agent.Bounds{Max: agent.Point{X: 2, Y: 2}}, agent.Point{X: 1}, agent.Point{X: 1, Y: 1}, agent.Point{X: 1, Y: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := walled.Route(agent.Point{}, agent.Point{X: 2}); !errors.Is(err, agent.ErrNoRoute) {
+		t.Errorf("Route() across a closed wall = %v, want %v", err, agent.ErrNoRoute)
+	}
+}
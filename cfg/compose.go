@@ -0,0 +1,214 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dark-enstein/chardot/agent"
+)
+
+// ctxKey is the type of the context keys of the package, so that they collide with no key of another package.
+type ctxKey string
+
+var SIMULATIONCTX = ctxKey("SIMULATIONCTX") // SIMULATIONCTX is the context key of the agent.Simulation a Parallel block runs its agents in.
+
+var (
+	ERRUNKNOWNSEQUENCE = errors.New("unknown sequence")   // ERRUNKNOWNSEQUENCE is wrapped by the error of a use action naming no sequence.
+	ERRRECURSIVEUSE    = errors.New("recursive sequence") // ERRRECURSIVEUSE is wrapped by the error of a sequence that ends up using itself.
+	ERRMISPLACED       = errors.New("misplaced action")   // ERRMISPLACED is wrapped by the error of a parallel block outside the script, or of a plain action in it.
+	ERRAMBIGUOUSACTION = errors.New("ambiguous action")   // ERRAMBIGUOUSACTION is wrapped by the error of an action that is more than one of name, repeat, use and parallel.
+)
+
+// kind returns what a is: its Name, or "repeat", "use" or "parallel". It is an error for a to be more than one.
+func (a *Action) kind() (string, error) {
+	var kinds []string
+	if a.Name != "" {
+		kinds = append(kinds, a.Name)
+	}
+	if a.Repeat != 0 || a.Actions != nil {
+		kinds = append(kinds, "repeat")
+	}
+	if a.Use != "" {
+		kinds = append(kinds, "use")
+	}
+	if a.Parallel != nil {
+		kinds = append(kinds, "parallel")
+	}
+	switch len(kinds) {
+	case 0:
+		return "", fmt.Errorf("%w: action needs a name, or one of repeat, use and parallel", ERRUNKNOWNACTION)
+	case 1:
+		return kinds[0], nil
+	}
+	return "", fmt.Errorf("%w: %v in one action", ERRAMBIGUOUSACTION, kinds)
+}
+
+// compiler turns Actions into Commands, resolving the sequences they use. Parallel blocks are only allowed in the
+// script: the top-level actions of a Config with agents, and the repeats and sequences they run.
+type compiler struct {
+	sequences map[string][]Action
+	agents    []string
+	script    bool
+	using     []string // using are the sequences being compiled, innermost last
+}
+
+// compiler returns the compiler of the actions of an agent, or of the script with script set.
+func (c *Config) compiler(script bool) *compiler {
+	cp := &compiler{sequences: c.Sequences, script: script}
+	for _, ac := range c.Agents {
+		cp.agents = append(cp.agents, ac.Name)
+	}
+	return cp
+}
+
+// CompileScript turns the top-level actions of a Config with agents into the Commands run once the actions of every
+// agent are done: parallel blocks, and the repeats and sequences that run them. It returns nil for a Config without
+// agents, whose top-level actions are those of its single agent.
+func (c *Config) CompileScript() ([]Command, error) {
+	if len(c.Agents) == 0 {
+		return nil, nil
+	}
+	return c.compiler(true).compile(c.A)
+}
+
+// compile turns acts into Commands. The error of an Action that cannot be carried out names its position in the
+// list, after the position of the repeat, sequence or parallel block it is part of.
+func (cp *compiler) compile(acts []Action) ([]Command, error) {
+	var ext []Command
+	for i := range acts {
+		cmd, err := cp.command(&acts[i])
+		if err != nil {
+			return nil, fmt.Errorf("action %d: %w", i+1, err)
+		}
+		ext = append(ext, cmd)
+	}
+	return ext, nil
+}
+
+func (cp *compiler) command(a *Action) (Command, error) {
+	kind, err := a.kind()
+	if err != nil {
+		return nil, err
+	}
+	if cp.script && kind != "repeat" && kind != "use" && kind != "parallel" {
+		return nil, fmt.Errorf("%w: with agents, top-level actions are parallel blocks, not %s", ERRMISPLACED, kind)
+	}
+	switch kind {
+	case "repeat":
+		if a.Repeat < 1 {
+			return nil, fmt.Errorf("repeat %d is less than 1", a.Repeat)
+		}
+		if len(a.Actions) == 0 {
+			return nil, fmt.Errorf("repeat has no actions")
+		}
+		cmds, err := cp.compile(a.Actions)
+		if err != nil {
+			return nil, fmt.Errorf("repeat: %w", err)
+		}
+		return &Repeat{times: a.Repeat, cmds: cmds}, nil
+	case "use":
+		acts, ok := cp.sequences[a.Use]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ERRUNKNOWNSEQUENCE, a.Use)
+		}
+		for _, name := range cp.using {
+			if name == a.Use {
+				return nil, fmt.Errorf("%w: %q uses itself", ERRRECURSIVEUSE, a.Use)
+			}
+		}
+		cp.using = append(cp.using, a.Use)
+		defer func() { cp.using = cp.using[:len(cp.using)-1] }()
+		cmds, err := cp.compile(acts)
+		if err != nil {
+			return nil, fmt.Errorf("sequence %s: %w", a.Use, err)
+		}
+		return &Sequence{name: a.Use, cmds: cmds}, nil
+	case "parallel":
+		if !cp.script {
+			return nil, fmt.Errorf("%w: parallel blocks go in the top-level actions of a config with agents", ERRMISPLACED)
+		}
+		return cp.parallel(a.Parallel)
+	}
+	return a.IntoCommand()
+}
+
+// parallel compiles the branches of a parallel block, in the order the agents they name are declared.
+func (cp *compiler) parallel(branches map[string][]Action) (Command, error) {
+	for name := range branches {
+		known := false
+		for _, ag := range cp.agents {
+			known = known || ag == name
+		}
+		if !known {
+			return nil, fmt.Errorf("parallel: agent %q is not declared", name)
+		}
+	}
+	branch := &compiler{sequences: cp.sequences, agents: cp.agents, using: cp.using}
+	p := &Parallel{branches: make(map[string][]Command)}
+	for _, name := range cp.agents {
+		acts, ok := branches[name]
+		if !ok {
+			continue
+		}
+		cmds, err := branch.compile(acts)
+		if err != nil {
+			return nil, fmt.Errorf("parallel: agent %s: %w", name, err)
+		}
+		p.branches[name] = cmds
+		p.agents = append(p.agents, name)
+	}
+	return p, nil
+}
+
+// Repeat runs its Commands in order, times times over.
+type Repeat struct {
+	times int
+	cmds  []Command
+}
+
+func (r *Repeat) Do(ctx context.Context) error {
+	for i := 0; i < r.times; i++ {
+		if err := RunCommands(ctx, r.cmds); err != nil {
+			return fmt.Errorf("repeat %d of %d: %w", i+1, r.times, err)
+		}
+	}
+	return nil
+}
+
+// Sequence runs the Commands of the sequence it is named after, in order.
+type Sequence struct {
+	name string
+	cmds []Command
+}
+
+func (s *Sequence) Do(ctx context.Context) error {
+	if err := RunCommands(ctx, s.cmds); err != nil {
+		return fmt.Errorf("sequence %s: %w", s.name, err)
+	}
+	return nil
+}
+
+// Parallel runs the Commands of each of its agents against that agent, all at once, and waits for all of them. The
+// agents are looked up in the agent.Simulation stored in the context under SIMULATIONCTX.
+type Parallel struct {
+	agents   []string
+	branches map[string][]Command
+}
+
+func (p *Parallel) Do(ctx context.Context) error {
+	sim, ok := ctx.Value(SIMULATIONCTX).(*agent.Simulation)
+	if !ok || sim == nil {
+		return fmt.Errorf("simulation not found in context")
+	}
+	work := make(map[string]agent.Work, len(p.branches))
+	for name, cmds := range p.branches {
+		cmds := cmds
+		work[name] = func(ctx context.Context, _ agent.Agent) error {
+			return RunCommands(ctx, cmds)
+		}
+	}
+	if err := sim.Run(ctx, work); err != nil {
+		return fmt.Errorf("parallel: %w", err)
+	}
+	return nil
+}
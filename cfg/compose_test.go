@@ -0,0 +1,197 @@
+package cfg_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/cfg"
+)
+
+// composed declares two agents and the sequences the tests of compose use.
+const composed = `
+agents:
+    - {name: a}
+    - {name: b}
+sequences:
+    square:
+        - repeat: 4
+          actions:
+              - use: side
+    side:
+        - {name: walk, direction: N, duration: 1}
+    loop:
+        - use: loop
+    ping:
+        - use: pong
+    pong:
+        - {name: wait, duration: 1}
+        - use: ping
+    split:
+        - parallel:
+              a:
+                  - {name: wait, duration: 1}
+`
+
+func TestCompileErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		script bool // script compiles the actions as the script, not as the actions of an agent.
+		yaml   string
+		is     error
+		msg    string
+	}{
+		{"repeat of 0", false, "{repeat: 0, actions: [{name: wait}]}", nil, "action 1: repeat 0 is less than 1"},
+		{"repeat of nothing", false, "{repeat: 2}", nil, "action 1: repeat has no actions"},
+		{"error in a repeat", false, "{repeat: 2, actions: [{name: wait}, {name: fly, direction: N}]}", cfg.ERRUNKNOWNACTION, "action 1: repeat: action 2:"},
+		{"unknown sequence", false, "{use: circle}", cfg.ERRUNKNOWNSEQUENCE, `"circle"`},
+		{"sequence using itself", false, "{use: loop}", cfg.ERRRECURSIVEUSE, `"loop" uses itself`},
+		{"sequences using each other", false, "{use: ping}", cfg.ERRRECURSIVEUSE, `sequence ping: action 1: sequence pong: action 2: recursive sequence: "ping" uses itself`},
+		{"parallel in an agent", false, "{parallel: {a: [{name: wait}]}}", cfg.ERRMISPLACED, "action 1:"},
+		{"parallel in a sequence of an agent", false, "{use: split}", cfg.ERRMISPLACED, "sequence split:"},
+		{"plain action in the script", true, "{name: wait, duration: 1}", cfg.ERRMISPLACED, "not wait"},
+		{"plain action in a repeat of the script", true, "{repeat: 2, actions: [{use: side}]}", cfg.ERRMISPLACED, "sequence side:"},
+		{"parallel in a parallel", true, "{parallel: {a: [{parallel: {b: [{name: wait}]}}]}}", cfg.ERRMISPLACED, "parallel: agent a: action 1:"},
+		{"undeclared agent", true, "{parallel: {c: [{name: wait}]}}", nil, `parallel: agent "c" is not declared`},
+		{"name and repeat", false, "{name: walk, repeat: 2, actions: [{name: wait}]}", cfg.ERRAMBIGUOUSACTION, "[walk repeat]"},
+		{"use and parallel", true, "{use: split, parallel: {a: [{name: wait}]}}", cfg.ERRAMBIGUOUSACTION, "[use parallel]"},
+		{"nothing", false, "{duration: 1}", cfg.ERRUNKNOWNACTION, "action 1:"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := parse(t, composed)
+			acts := parse(t, "actions: ["+tc.yaml+"]").A
+			var err error
+			if tc.script {
+				c.A = acts
+				_, err = c.CompileScript()
+			} else {
+				_, err = c.Compile(acts)
+			}
+			switch {
+			case err == nil:
+				t.Fatalf("compiling %s succeeded, want an error", tc.yaml)
+			case tc.is != nil && !errors.Is(err, tc.is):
+				t.Errorf("compiling %s = %v, want %v", tc.yaml, err, tc.is)
+			case !strings.Contains(err.Error(), tc.msg):
+				t.Errorf("compiling %s = %v, want %q in it", tc.yaml, err, tc.msg)
+			}
+		})
+	}
+}
+
+func TestCompileResolvesSequences(t *testing.T) {
+	c := parse(t, composed)
+	// square uses side four times over: using a sequence again is no recursion
+	acts := parse(t, "actions: [{use: square}, {use: side}, {repeat: 2, actions: [{use: square}]}]").A
+	cmds, err := c.Compile(acts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, cmd := range cmds {
+		types = append(types, fmt.Sprintf("%T", cmd))
+	}
+	if got, want := strings.Join(types, " "), "*cfg.Sequence *cfg.Sequence *cfg.Repeat"; got != want {
+		t.Errorf("compiled %s, want %s", got, want)
+	}
+	if _, err := cfg.Compile(parse(t, "actions: [{use: side}]").A); !errors.Is(err, cfg.ERRUNKNOWNSEQUENCE) {
+		t.Errorf("Compile() of a use without a Config = %v, want %v", err, cfg.ERRUNKNOWNSEQUENCE)
+	}
+
+	c.A = parse(t, "actions: [{parallel: {b: [{use: square}], a: [{use: side}]}}, {use: split}]").A
+	script, err := c.CompileScript()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(script) != 2 {
+		t.Fatalf("compiled a script of %d commands, want 2", len(script))
+	}
+	if _, ok := script[0].(*cfg.Parallel); !ok {
+		t.Errorf("compiled %T, want *cfg.Parallel", script[0])
+	}
+	if script, err := (&cfg.Config{A: c.A}).CompileScript(); script != nil || err != nil {
+		t.Errorf("CompileScript() without agents = %v, %v, want nothing", script, err)
+	}
+}
+
+func TestRepeatsRunTheirCount(t *testing.T) {
+	c := parse(t, `
+clock: virtual
+report: silent
+logLevel: ERROR
+walkSpeed: 1
+sequences:
+    step:
+        - {name: walk, direction: N, duration: 1}
+        - {name: walk, direction: W, duration: 1}
+actions:
+    - repeat: 3
+      actions:
+          - use: step
+    - repeat: 2
+      actions:
+          - repeat: 2
+            actions:
+                - {name: walk, direction: S, duration: 1}
+`)
+	report, err := c.Simulate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := report.Agents[0]
+	if want := (agent.Point{X: 3, Y: -1}); ran.Position != want || ended(ran.Path) != 10*time.Second {
+		t.Errorf("ended at %+v after %v, want %+v after 10s", ran.Position, ended(ran.Path), want)
+	}
+}
+
+func TestParallelRunsOnVirtualClock(t *testing.T) {
+	c := parse(t, `
+clock: virtual
+report: silent
+logLevel: ERROR
+walkSpeed: 1
+agents:
+    - name: a
+      actions:
+          - {name: walk, direction: N, duration: 2}
+    - name: b
+      walkSpeed: 2
+actions:
+    - parallel:
+          a:
+              - {name: walk, direction: E, duration: 1}
+          b:
+              - {name: wait, duration: 1}
+              - {name: walk, direction: N, duration: 3}
+    - parallel:
+          b:
+              - {name: walk, direction: W, duration: 1}
+`)
+	report, err := c.Simulate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first block starts once a is done with its own actions, at 2s; the second once b is done with the first
+	want := map[string]struct {
+		at         agent.Point
+		start, end time.Duration
+	}{
+		"a": {agent.Point{X: -1, Y: 2}, 0, 3 * time.Second},
+		"b": {agent.Point{X: 2, Y: 6}, 2 * time.Second, 7 * time.Second},
+	}
+	for _, ran := range report.Agents {
+		w := want[ran.Name]
+		if len(ran.Path.S) == 0 {
+			t.Fatalf("%s took no steps", ran.Name)
+		}
+		start := ran.Path.S[0].Start.Sub(cfg.VIRTUALEPOCH)
+		if ran.Position != w.at || start != w.start || ended(ran.Path) != w.end {
+			t.Errorf("%s went from %v to %v, ending at %+v, want from %v to %v, ending at %+v",
+				ran.Name, start, ended(ran.Path), ran.Position, w.start, w.end, w.at)
+		}
+	}
+}
@@ -2,30 +2,96 @@ package cfg
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"github.com/dark-enstein/chardot/agent"
 	"github.com/dark-enstein/chardot/internal/ilog"
 	"github.com/dark-enstein/chardot/util"
-	"log"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 )
 
 var (
 	ERRSPEEDNOTDEFINED     = fmt.Errorf("Speed not defined for walk\n\n")
 	ERRORNOTVALIDRETURNING = fmt.Errorf("LogLevel passed in invalid. Using INFO.")
+	ERRCLOCKNOTVALID       = fmt.Errorf("Clock passed in invalid. Use %q or %q", REALCLOCK, VIRTUALCLOCK)
+	ERRREPORTNOTVALID      = fmt.Errorf("Report passed in invalid. Use %q, %q or %q", TEXTREPORT, JSONREPORT, SILENTREPORT)
+	ERRINVALIDCONFIG       = errors.New("invalid config")    // ERRINVALIDCONFIG is wrapped by every error found in the configuration itself.
+	ERRUNKNOWNACTION       = errors.New("unknown action")    // ERRUNKNOWNACTION is wrapped by the error of an action name not recognized.
+	ERRNEGATIVEDURATION    = errors.New("negative duration") // ERRNEGATIVEDURATION is wrapped by the error of an action lasting less than nothing.
+	ERRNOTARGET            = errors.New("no target")         // ERRNOTARGET is wrapped by the error of a goto or waypoints action with nowhere to go.
+	ERRGAITNOTVALID        = fmt.Errorf("Gait passed in invalid. Use %q, %q or a distance beyond which to run", WALKGAIT, RUNGAIT)
+	ERREXPORT              = errors.New("exporting tracks") // ERREXPORT is wrapped by the error of tracks that could not be written out.
 	DEFAULTWALKSPEED       = agent.Speed(0)
 	DEFAULTRUNSPEED        = agent.Speed(0)
 )
 
+const (
+	REALCLOCK    = "real"    // REALCLOCK runs actions against the wall clock. It is the default.
+	VIRTUALCLOCK = "virtual" // VIRTUALCLOCK runs actions against an agent.VirtualClock, completing them without sleeping.
+)
+
+// VIRTUALEPOCH is the time a VIRTUALCLOCK starts at, so that the timestamps of a virtual run are the same every time.
+var VIRTUALEPOCH = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+const (
+	WALKGAIT = "walk" // WALKGAIT walks every leg of a goto or waypoints action. It is the default.
+	RUNGAIT  = "run"  // RUNGAIT runs every leg of a goto or waypoints action.
+)
+
+const (
+	TEXTREPORT   = "text"   // TEXTREPORT reports to stdout as human-readable text. It is the default.
+	JSONREPORT   = "json"   // JSONREPORT reports to stdout as JSON, one object per line.
+	SILENTREPORT = "silent" // SILENTREPORT reports nothing.
+)
+
 type Config struct {
+	A          []Action      `yaml:"actions"`
+	LogLevel   string        `yaml:"logLevel"`
+	WalkSpeed  string        `yaml:"walkSpeed"`
+	RunSpeed   string        `yaml:"runSpeed"`
+	Gait       string        `yaml:"gait"`
+	World      *WorldConfig  `yaml:"world"`
+	Clock      string        `yaml:"clock"`
+	Report     string        `yaml:"report"`
+	Stats      bool          `yaml:"stats"`
+	Output     string        `yaml:"output"`
+	OutputFile string        `yaml:"outputFile"`
+	Agents     []AgentConfig `yaml:"agents"`
+	Mode       string        `yaml:"mode"`
+	Race       *RaceConfig   `yaml:"race"`
+	Expect     *Expect       `yaml:"expect"`
+
+	Sequences map[string][]Action `yaml:"sequences"` // Sequences are named lists of actions, run wherever an action uses them.
+
+	Stdout io.Writer `yaml:"-"` // Stdout is where Run exports tracks without an OutputFile, os.Stdout when nil.
+}
+
+// WorldConfig sets the World agents move over: the ASCII map in Map, as agent.LoadWorld reads it. With FourWay set,
+// routes only go along the axes.
+type WorldConfig struct {
+	Map     string `yaml:"map"`
+	FourWay bool   `yaml:"fourWay"`
+}
+
+// AgentConfig configures one agent of A multi-agent simulation. Speeds left empty are inherited from the Config.
+type AgentConfig struct {
+	Name      string   `yaml:"name"`
+	Kind      string   `yaml:"kind"`
 	A         []Action `yaml:"actions"`
-	LogLevel  string   `yaml:"logLevel"`
 	WalkSpeed string   `yaml:"walkSpeed"`
 	RunSpeed  string   `yaml:"runSpeed"`
+	Gait      string   `yaml:"gait"`
+	NapOdds   float64  `yaml:"napOdds"`
+	NapSec    int      `yaml:"nap"`
+	Seed      int64    `yaml:"seed"`
+	Expect    *Expect  `yaml:"expect"`
 }
 
 func NewConfig(loglevel, walkS, runS string, acts ...Action) *Config {
-	log.Println("set up new config")
 	return &Config{
 		A:         acts,
 		LogLevel:  loglevel,
@@ -35,77 +101,429 @@ func NewConfig(loglevel, walkS, runS string, acts ...Action) *Config {
 }
 
 type Configurer interface {
-	SetUp() func() []error
+	SetUp(ctx context.Context) error
 }
 
-func (c *Config) InitSetUp() (context.Context, error) {
-	log.Println("initializing setup")
+// InitSetUp derives the context the configured agent runs in from ctx: it carries the logger, the clock and the agent.
+func (c *Config) InitSetUp(ctx context.Context) (context.Context, error) {
+	ctx, err := c.initContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	//Values passed in:
+
+	ag, err := c.SetUpAgent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = context.WithValue(ctx, agent.AGENT, ag)
+
+	return ctx, err
+}
+
+// initContext stores the logger, the clock, the world and the reporter shared by every agent in ctx.
+func (c *Config) initContext(ctx context.Context) (context.Context, error) {
 	if c.LogLevel == "" {
 		c.LogLevel = "INFO"
 	}
 
-	if c.LogLevel != "INFO" && c.LogLevel != "DEBUG" && c.LogLevel != "ERROR" && c.LogLevel != "PANIC" {
-		log.Println(ERRORNOTVALIDRETURNING)
+	logger, err := ilog.NewLogger(c.LogLevel)
+	if err != nil {
 		c.LogLevel = "INFO"
+		logger, _ = ilog.NewLogger(c.LogLevel)
+		logger.Log(ilog.ERROR, "%v", ERRORNOTVALIDRETURNING)
 	}
+	logger.Log(ilog.DEBUG, "initializing setup")
 
-	logger, err := ilog.NewLogger(c.LogLevel)
+	ctx = context.WithValue(ctx, ilog.LOGGERCTX, logger)
+
+	clock, err := c.ResolveClock()
 	if err != nil {
-		log.Println(err)
+		return nil, invalid(err)
 	}
+	ctx = context.WithValue(ctx, agent.CLOCKCTX, clock)
 
-	ctx := context.WithValue(context.Background(), ilog.LOGGERCTX, logger)
+	if c.World != nil {
+		world, err := c.ResolveWorld()
+		if err != nil {
+			return nil, invalid(err)
+		}
+		ctx = context.WithValue(ctx, agent.WORLDCTX, world)
+	}
 
-	//Values passed in:
+	ctx, _, err = c.withReporter(ctx)
+	return ctx, err
+}
 
-	ag, err := c.SetUpAgent(ctx)
+// withReporter returns ctx carrying the agent.Reporter the configuration names, along with the Reporter. A Reporter
+// ctx already carries is kept.
+func (c *Config) withReporter(ctx context.Context) (context.Context, agent.Reporter, error) {
+	if rep, err := agent.GetReporterFromCtx(ctx); err == nil {
+		return ctx, rep, nil
+	}
+	rep, err := c.ResolveReporter()
+	if err != nil {
+		return nil, nil, invalid(err)
+	}
+	return context.WithValue(ctx, agent.REPORTERCTX, rep), rep, nil
+}
+
+// SetUp builds the configured agents and runs their actions. It stops an agent at the first of its actions that
+// fails, and aborts the remaining ones once ctx is cancelled. When several agents are configured, their paths are
+// printed once all of them are done. With Stats set, the Stats of the path of every agent are reported last; with
+// Output or OutputFile set, the tracks of every agent are then exported. Races report their standings instead.
+// Expectations the run does not meet are returned as errors wrapping ERREXPECTATION.
+func (c *Config) SetUp(ctx context.Context) error {
+	outcomes, err := c.Run(ctx)
+	return errors.Join(err, Failures(outcomes))
+}
+
+// Run is SetUp, returning the Outcome of every expectation of the configuration, checked once the agents are done,
+// rather than failing on those not met.
+func (c *Config) Run(ctx context.Context) ([]Outcome, error) {
+	ctx, rep, err := c.withReporter(ctx)
 	if err != nil {
 		return nil, err
 	}
-	ctx = context.WithValue(ctx, agent.AGENT, ag)
+	format, err := c.ResolveOutput()
+	if err != nil {
+		return nil, invalid(err)
+	}
+	switch c.Mode {
+	case "", ACTIONSMODE:
+	case RACEMODE:
+		results, err := c.RunRace(ctx)
+		if results != nil {
+			agent.ReportStandings(rep, results)
+		}
+		return nil, err
+	default:
+		return nil, invalid(fmt.Errorf("mode %v not recognized", c.Mode))
+	}
+	report, err := c.Simulate(ctx)
+	if report == nil {
+		return nil, err
+	}
+	if len(c.Agents) > 0 {
+		report.ReportTo(rep)
+	}
+	if c.Stats {
+		report.ReportStats(rep)
+	}
+	if format != "" {
+		stdout := c.Stdout
+		if stdout == nil {
+			stdout = os.Stdout
+		}
+		err = errors.Join(err, c.Export(stdout, format, report))
+	}
+	return c.Verify(report), err
+}
 
-	return ctx, err
+// Check resolves everything the configuration sets without building or running any agent: the log level, the clock,
+// the report, the output, the world, the mode, and the kind, speeds, gait and actions of every agent. It returns
+// every problem it finds, joined, each wrapping ERRINVALIDCONFIG.
+func (c *Config) Check() error {
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, invalid(err))
+		}
+	}
+	if c.LogLevel != "" {
+		if _, err := ilog.NewLogger(c.LogLevel); err != nil {
+			check(fmt.Errorf("logLevel %q not recognized", c.LogLevel))
+		}
+	}
+	_, err := c.ResolveClock()
+	check(err)
+	_, err = c.ResolveReporter()
+	check(err)
+	_, err = c.ResolveOutput()
+	check(err)
+	if _, err := c.ResolveWorld(); err != nil {
+		check(fmt.Errorf("world: %w", err))
+	}
+	race := false
+	switch c.Mode {
+	case "", ACTIONSMODE:
+	case RACEMODE:
+		race = true
+		if c.Expect != nil {
+			check(fmt.Errorf("expect is not checked in mode %v", RACEMODE))
+		}
+		if c.Race == nil {
+			check(fmt.Errorf("mode %v needs a race section", RACEMODE))
+		} else {
+			if _, err := ParseDirection(c.Race.Direction); err != nil {
+				check(fmt.Errorf("race: %w", err))
+			}
+			if c.Race.Finish <= 0 {
+				check(fmt.Errorf("race: finish %v must be greater than 0", c.Race.Finish))
+			}
+		}
+	default:
+		check(fmt.Errorf("mode %v not recognized", c.Mode))
+	}
+
+	for _, ac := range c.agentConfigs() {
+		sub := c.forAgent(ac)
+		switch ac.Kind {
+		case "", HAREKIND, TORTOISEKIND, NAPPINGHAREKIND:
+		default:
+			check(fmt.Errorf("agent %s: agent kind %v not recognized", ac.Name, ac.Kind))
+		}
+		if sub.WalkSpeed != "" {
+			if _, err := parseSpeed("walkSpeed", sub.WalkSpeed); err != nil {
+				check(fmt.Errorf("agent %s: %w", ac.Name, err))
+			}
+		}
+		if sub.RunSpeed != "" {
+			if _, err := parseSpeed("runSpeed", sub.RunSpeed); err != nil {
+				check(fmt.Errorf("agent %s: %w", ac.Name, err))
+			}
+		}
+		if _, err := sub.ResolveGait(); err != nil {
+			check(fmt.Errorf("agent %s: %w", ac.Name, err))
+		}
+		if race {
+			continue
+		}
+		if _, err := c.Compile(ac.A); err != nil {
+			check(fmt.Errorf("agent %s: %w", ac.Name, err))
+		}
+	}
+	if !race {
+		if _, err := c.CompileScript(); err != nil {
+			check(fmt.Errorf("script: %w", err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
-func (c *Config) SetUp() error {
-	ctx, err := c.InitSetUp()
+// Export writes the tracks of report in format to OutputFile, or to stdout when it is empty. Its errors wrap ERREXPORT.
+func (c *Config) Export(stdout io.Writer, format string, report *agent.SimulationReport) (err error) {
+	w := stdout
+	if c.OutputFile != "" {
+		f, err := os.Create(c.OutputFile)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ERREXPORT, err)
+		}
+		defer func() {
+			if cerr := f.Close(); cerr != nil && err == nil {
+				err = fmt.Errorf("%w: %w", ERREXPORT, cerr)
+			}
+		}()
+		w = f
+	}
+	if err := agent.Export(w, format, report.Tracks()...); err != nil {
+		return fmt.Errorf("%w: %w", ERREXPORT, err)
+	}
+	return nil
+}
 
+// Simulate builds every configured agent, registers it in an agent.Simulation and runs the actions of each
+// concurrently on their shared clock. A Config without agents runs its top-level actions on A single agent named
+// agent.AGENT. With agents, the top-level actions are a script, run once every agent is done with its own: its
+// parallel blocks start the agents they name together, and wait for all of them before the next block.
+func (c *Config) Simulate(ctx context.Context) (*agent.SimulationReport, error) {
+	ctx, err := c.initContext(ctx)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	script, err := c.CompileScript()
+	if err != nil {
+		return nil, invalid(fmt.Errorf("script: %w", err))
 	}
-	var ext []Command
-	for i := 0; i < len(c.A); i++ {
-		cmd, err := c.A[i].IntoCommand()
+	sim := agent.NewSimulation(ctx)
+	work := make(map[string]agent.Work)
+	for _, ac := range c.agentConfigs() {
+		ext, err := c.Compile(ac.A)
+		if err != nil {
+			return nil, invalid(fmt.Errorf("agent %s: %w", ac.Name, err))
+		}
+		ag, err := c.SetUpKind(ctx, ac)
 		if err != nil {
-			break
+			return nil, invalid(fmt.Errorf("agent %s: %w", ac.Name, err))
+		}
+		if err := sim.Register(ac.Name, ag); err != nil {
+			return nil, invalid(err)
+		}
+		work[ac.Name] = func(ctx context.Context, _ agent.Agent) error {
+			return RunCommands(ctx, ext)
 		}
-		ext = append(ext, cmd)
 	}
-	if err != nil {
+	err = sim.Run(ctx, work)
+	if err == nil && len(script) > 0 {
+		err = RunCommands(context.WithValue(ctx, SIMULATIONCTX, sim), script)
+	}
+	report := sim.Report()
+	if len(c.Agents) == 0 && len(report.Agents) == 1 {
+		return report, report.Agents[0].Err
+	}
+	return report, err
+}
+
+// agentConfigs returns the configured agents, or A single agent named agent.AGENT carrying the top-level actions.
+func (c *Config) agentConfigs() []AgentConfig {
+	if len(c.Agents) > 0 {
+		return c.Agents
+	}
+	return []AgentConfig{{Name: agent.AGENT, A: c.A}}
+}
+
+// forAgent returns the Config of ac, inheriting the speeds and the gait it leaves empty.
+func (c *Config) forAgent(ac AgentConfig) *Config {
+	sub := *c
+	sub.A, sub.Agents = ac.A, nil
+	if ac.WalkSpeed != "" {
+		sub.WalkSpeed = ac.WalkSpeed
+	}
+	if ac.RunSpeed != "" {
+		sub.RunSpeed = ac.RunSpeed
+	}
+	if ac.Gait != "" {
+		sub.Gait = ac.Gait
+	}
+	return &sub
+}
+
+// invalid marks err as an error in the configuration itself, so that errors.Is(err, ERRINVALIDCONFIG) holds.
+func invalid(err error) error {
+	if errors.Is(err, ERRINVALIDCONFIG) {
 		return err
 	}
-	return func() error {
-		for i := 0; i < len(ext); i++ {
-			err := ext[i].Do(ctx)
-			if err != nil {
-				break
-			}
+	return fmt.Errorf("%w: %w", ERRINVALIDCONFIG, err)
+}
+
+// Compile turns A list of Actions into the Commands that carry them out, repeats included. The error of an Action
+// that cannot be carried out names its position in the list. Actions using a sequence need the Config defining it:
+// use Config.Compile for those.
+func Compile(acts []Action) ([]Command, error) {
+	return (&compiler{}).compile(acts)
+}
+
+// Compile turns the list of Actions of one agent into the Commands that carry them out, resolving the sequences of
+// the configuration the actions use.
+func (c *Config) Compile(acts []Action) ([]Command, error) {
+	return c.compiler(false).compile(acts)
+}
+
+// RunCommands runs the Commands in order against the agent stored in ctx. It stops at the first Command that fails,
+// and aborts the remaining ones once ctx is cancelled.
+func RunCommands(ctx context.Context, ext []Command) error {
+	for i := 0; i < len(ext); i++ {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("aborted before action %d of %d: %w", i+1, len(ext), err)
 		}
-		return err
-	}()
+		if err := ext[i].Do(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
+// ResolveSpeed returns the walking and running speeds of the configuration. A speed left empty defaults to
+// DEFAULTWALKSPEED or DEFAULTRUNSPEED; one that is not A non-negative integer yields an error wrapping
+// agent.ErrInvalidSpeed.
 func (c *Config) ResolveSpeed(ctx context.Context) (w, r *agent.Speed, err error) {
 	clog, err := ilog.GetLoggerFromCtx(ctx)
 	ilog.CheckErrLog(err)
+	if err != nil {
+		clog = agent.Clog
+	}
+	w, r = DEFAULTWALKSPEED.Ptr(), DEFAULTRUNSPEED.Ptr()
 	if c.WalkSpeed == "" {
 		clog.Log(ilog.DEBUG, "WalkSpeed is not defined in the configuration. Defaulting to %v", DEFAULTWALKSPEED)
+	} else if w, err = parseSpeed("walkSpeed", c.WalkSpeed); err != nil {
+		return nil, nil, err
 	}
 
 	if c.RunSpeed == "" {
 		clog.Log(ilog.DEBUG, "RunSpeed is not defined in the configuration. Defaulting to %v", DEFAULTRUNSPEED)
+	} else if r, err = parseSpeed("runSpeed", c.RunSpeed); err != nil {
+		return nil, nil, err
+	}
+	return w, r, nil
+}
+
+// parseSpeed parses the speed set under key.
+func parseSpeed(key, s string) (*agent.Speed, error) {
+	i, err := util.Atoi(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %v", agent.ErrInvalidSpeed, key, err)
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("%w: %s %d is negative", agent.ErrInvalidSpeed, key, i)
+	}
+	return agent.Speed(i).Ptr(), nil
+}
+
+// ResolveClock returns the agent.Clock named by the configuration, defaulting to the wall clock.
+func (c *Config) ResolveClock() (agent.Clock, error) {
+	switch c.Clock {
+	case "", REALCLOCK:
+		return agent.RealClock{}, nil
+	case VIRTUALCLOCK:
+		return agent.NewVirtualClock(VIRTUALEPOCH), nil
+	}
+	return nil, ERRCLOCKNOTVALID
+}
+
+// ResolveOutput returns the format tracks are exported in: Output, or agent.JSONEXPORT when only OutputFile is set.
+// It is empty when nothing is to be exported, and an error wrapping agent.ErrUnknownFormat when Output is not one of
+// agent.JSONEXPORT, agent.CSVEXPORT and agent.GEOJSONEXPORT.
+func (c *Config) ResolveOutput() (string, error) {
+	switch c.Output {
+	case "":
+		if c.OutputFile != "" {
+			return agent.JSONEXPORT, nil
+		}
+		return "", nil
+	case agent.JSONEXPORT, agent.CSVEXPORT, agent.GEOJSONEXPORT:
+		return c.Output, nil
+	}
+	return "", fmt.Errorf("%w: %q, use %q, %q or %q", agent.ErrUnknownFormat, c.Output, agent.JSONEXPORT, agent.CSVEXPORT, agent.GEOJSONEXPORT)
+}
+
+// ResolveWorld loads the agent.World of the configuration from its map file. It is nil without a world.
+func (c *Config) ResolveWorld() (*agent.World, error) {
+	if c.World == nil {
+		return nil, nil
+	}
+	if c.World.Map == "" {
+		return nil, fmt.Errorf("world needs a map")
+	}
+	f, err := os.Open(c.World.Map)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	world, err := agent.LoadWorld(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", c.World.Map, err)
+	}
+	world.Diagonals = !c.World.FourWay
+	return world, nil
+}
+
+// ResolveReporter returns the agent.Reporter named by the configuration, writing to stdout. It defaults to text.
+func (c *Config) ResolveReporter() (agent.Reporter, error) {
+	return c.ResolveReporterTo(os.Stdout)
+}
+
+// ResolveReporterTo returns the agent.Reporter named by the configuration, writing to w.
+func (c *Config) ResolveReporterTo(w io.Writer) (agent.Reporter, error) {
+	switch c.Report {
+	case "", TEXTREPORT:
+		return agent.NewTextReporter(w), nil
+	case JSONREPORT:
+		return agent.NewJSONReporter(w), nil
+	case SILENTREPORT:
+		return agent.SilentReporter{}, nil
 	}
-	return agent.Speed(util.MustAtoi(c.WalkSpeed)).Ptr(), agent.Speed(util.MustAtoi(c.RunSpeed)).Ptr(), err
+	return nil, ERRREPORTNOTVALID
 }
 
 func (c *Config) SetUpAgent(ctx context.Context) (agent.Agent, error) {
@@ -124,7 +542,31 @@ func (c *Config) SetUpAgent(ctx context.Context) (agent.Agent, error) {
 	if err != nil {
 		return nil, err
 	}
-	return agent.NewHare(ctx, *walkS, *runS), err
+	gait, err := c.ResolveGait()
+	if err != nil {
+		return nil, err
+	}
+	h, err := agent.NewHare(ctx, *walkS, *runS, agent.WithGait(gait))
+	if err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ResolveGait returns the agent.Gait of the configuration: WALKGAIT, RUNGAIT, or a distance beyond which legs are
+// run rather than walked. It defaults to WALKGAIT.
+func (c *Config) ResolveGait() (agent.Gait, error) {
+	switch c.Gait {
+	case "", WALKGAIT:
+		return agent.WALKGAIT, nil
+	case RUNGAIT:
+		return agent.RUNGAIT, nil
+	}
+	n, err := util.Atoi(c.Gait)
+	if err != nil || n < 0 {
+		return nil, ERRGAITNOTVALID
+	}
+	return agent.RunBeyond(agent.Coordinate(n)), nil
 }
 
 type Command interface {
@@ -132,27 +574,61 @@ type Command interface {
 }
 
 type Action struct {
-	Name        string `yaml:"name"`
-	DurationSec int    `yaml:"duration"`
-	Direction   string `yaml:"direction"`
+	Name        string        `yaml:"name"`
+	DurationSec int           `yaml:"duration"`
+	Direction   string        `yaml:"direction"`
+	File        string        `yaml:"file"`      // File is the recording a replay action reads: a .json, .csv or .gpx file.
+	Track       string        `yaml:"track"`     // Track names the recording in File to replay, the first one otherwise.
+	To          *agent.Point  `yaml:"to"`        // To is the point a goto action goes to, as {x: 10, y: -4}.
+	Waypoints   []agent.Point `yaml:"waypoints"` // Waypoints are the points a waypoints action goes through, in order.
+
+	Repeat   int                 `yaml:"repeat"`   // Repeat is how many times the Actions of a repeat run, in order.
+	Actions  []Action            `yaml:"actions"`  // Actions are the actions a repeat runs.
+	Use      string              `yaml:"use"`      // Use names the sequence to run in place of the action.
+	Parallel map[string][]Action `yaml:"parallel"` // Parallel maps agents to the actions they run at once with the others.
 }
 
 func (a *Action) IntoCommand() (Command, error) {
 	if a.DurationSec < 0 {
-		return nil, fmt.Errorf("duration %v is negative. invalid", a.DurationSec)
+		return nil, fmt.Errorf("%w: %v", ERRNEGATIVEDURATION, a.DurationSec)
 	}
-	direction := agent.Direction(-1)
-	switch a.Direction {
-	case "N":
-		direction = agent.NORTH
-	case "S":
-		direction = agent.SOUTH
-	case "E":
-		direction = agent.EAST
-	case "W":
-		direction = agent.WEST
-	default:
-		return nil, fmt.Errorf("direction %v not recognized\n\n", a.Direction)
+	if a.Name == "wait" {
+		return &Wait{
+			time: time.Second * time.Duration(a.DurationSec),
+			ctx:  nil,
+		}, nil
+	}
+	if a.Name == "goto" {
+		if a.To == nil {
+			return nil, fmt.Errorf("%w: goto", ERRNOTARGET)
+		}
+		return &Goto{
+			waypoints: []agent.Point{*a.To},
+			ctx:       nil,
+		}, nil
+	}
+	if a.Name == "waypoints" {
+		if len(a.Waypoints) == 0 {
+			return nil, fmt.Errorf("%w: waypoints", ERRNOTARGET)
+		}
+		return &Goto{
+			waypoints: a.Waypoints,
+			ctx:       nil,
+		}, nil
+	}
+	if a.Name == "replay" {
+		rec, err := LoadRecording(a.File, a.Track)
+		if err != nil {
+			return nil, err
+		}
+		return &Replay{
+			rec: rec,
+			ctx: nil,
+		}, nil
+	}
+	direction, err := ParseDirection(a.Direction)
+	if err != nil {
+		return nil, err
 	}
 
 	switch a.Name {
@@ -169,7 +645,30 @@ func (a *Action) IntoCommand() (Command, error) {
 			ctx:       nil,
 		}, nil
 	}
-	return nil, fmt.Errorf("action %v not recognized\n\n", a.Name)
+	return nil, fmt.Errorf("%w: %q", ERRUNKNOWNACTION, a.Name)
+}
+
+// ParseDirection returns the agent.Direction named by s, one of N, S, E, W, NE, NW, SE and SW.
+func ParseDirection(s string) (agent.Direction, error) {
+	switch s {
+	case "N":
+		return agent.NORTH, nil
+	case "S":
+		return agent.SOUTH, nil
+	case "E":
+		return agent.EAST, nil
+	case "W":
+		return agent.WEST, nil
+	case "NE":
+		return agent.NORTHEAST, nil
+	case "NW":
+		return agent.NORTHWEST, nil
+	case "SE":
+		return agent.SOUTHEAST, nil
+	case "SW":
+		return agent.SOUTHWEST, nil
+	}
+	return agent.Direction(-1), fmt.Errorf("%w: %q", agent.ErrUnknownDirection, s)
 }
 
 type Walk struct {
@@ -180,9 +679,13 @@ type Walk struct {
 
 func (w *Walk) Do(ctx context.Context) error {
 	ag, err := agent.GetAgentFromCtx(ctx)
-	ilog.CheckErrLog(err)
-	ag.Walk(w.time, w.direction)
-	return nil
+	if err != nil {
+		return err
+	}
+	if ca, ok := ag.(agent.ContextAgent); ok {
+		return ca.WalkContext(ctx, w.time, w.direction)
+	}
+	return ag.Walk(w.time, w.direction)
 }
 
 type Run struct {
@@ -193,7 +696,88 @@ type Run struct {
 
 func (w *Run) Do(ctx context.Context) error {
 	ag, err := agent.GetAgentFromCtx(ctx)
-	ilog.CheckErrLog(err)
-	ag.Run(w.time, w.direction)
-	return nil
+	if err != nil {
+		return err
+	}
+	if ca, ok := ag.(agent.ContextAgent); ok {
+		return ca.RunContext(ctx, w.time, w.direction)
+	}
+	return ag.Run(w.time, w.direction)
+}
+
+type Wait struct {
+	time time.Duration
+	ctx  context.Context
+}
+
+func (w *Wait) Do(ctx context.Context) error {
+	ag, err := agent.GetAgentFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if ca, ok := ag.(agent.ContextAgent); ok {
+		return ca.WaitContext(ctx, w.time)
+	}
+	return ag.Wait(w.time)
+}
+
+// LoadRecording reads the recording named track from file, in the format its extension names: .json, .csv or .gpx.
+// With track empty, the first recording in file is returned.
+func LoadRecording(file, track string) (agent.Recording, error) {
+	if file == "" {
+		return agent.Recording{}, fmt.Errorf("%w: replay needs a file", agent.ErrInvalidRecording)
+	}
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+	f, err := os.Open(file)
+	if err != nil {
+		return agent.Recording{}, err
+	}
+	defer f.Close()
+	recs, err := agent.Import(f, format)
+	if err != nil {
+		return agent.Recording{}, fmt.Errorf("%s: %w", file, err)
+	}
+	for _, rec := range recs {
+		if track == "" || rec.Agent == track {
+			return rec, nil
+		}
+	}
+	if track == "" {
+		return agent.Recording{}, fmt.Errorf("%s: %w: no recording", file, agent.ErrInvalidRecording)
+	}
+	return agent.Recording{}, fmt.Errorf("%s: %w: no recording named %q", file, agent.ErrInvalidRecording, track)
+}
+
+type Replay struct {
+	rec agent.Recording
+	ctx context.Context
+}
+
+func (w *Replay) Do(ctx context.Context) error {
+	ag, err := agent.GetAgentFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	rp, ok := ag.(agent.Replayer)
+	if !ok {
+		return fmt.Errorf("agent %T cannot replay a recording", ag)
+	}
+	return rp.ReplayContext(ctx, w.rec)
+}
+
+type Goto struct {
+	waypoints []agent.Point
+	ctx       context.Context
+}
+
+func (g *Goto) Do(ctx context.Context) error {
+	ag, err := agent.GetAgentFromCtx(ctx)
+	if err != nil {
+		return err
+	}
+	nv, ok := ag.(agent.Navigator)
+	if !ok {
+		return fmt.Errorf("agent %T cannot go to a point", ag)
+	}
+	return nv.FollowWaypointsContext(ctx, g.waypoints)
 }
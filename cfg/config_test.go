@@ -0,0 +1,187 @@
+package cfg_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/cfg"
+)
+
+func TestResolveClockVirtualStartsAtEpoch(t *testing.T) {
+	for i := 0; i < 2; i++ {
+		clock, err := (&cfg.Config{Clock: cfg.VIRTUALCLOCK}).ResolveClock()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := clock.(*agent.VirtualClock); !ok {
+			t.Fatalf("ResolveClock() = %T, want *agent.VirtualClock", clock)
+		}
+		if got := clock.Now(); !got.Equal(cfg.VIRTUALEPOCH) {
+			t.Errorf("virtual clock starts at %v, want %v", got, cfg.VIRTUALEPOCH)
+		}
+	}
+}
+
+// cancelling is a Reporter that keeps every Report and cancels its context once it has seen paces Reports of kind
+// REPORTPACE.
+type cancelling struct {
+	m       sync.Mutex
+	paces   int
+	cancel  context.CancelFunc
+	reports []agent.Report
+}
+
+func (c *cancelling) Report(r agent.Report) {
+	c.m.Lock()
+	defer c.m.Unlock()
+	c.reports = append(c.reports, r)
+	if r.Kind == agent.REPORTPACE {
+		if c.paces--; c.paces == 0 {
+			c.cancel()
+		}
+	}
+}
+
+func TestSetUpStopsOnCancel(t *testing.T) {
+	c := parse(t, `
+clock: virtual
+logLevel: ERROR
+walkSpeed: 1
+actions:
+    - {name: walk, direction: N, duration: 5}
+    - {name: wait, duration: 2}
+    - {name: walk, direction: W, duration: 3}
+`)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	rep := &cancelling{paces: 2, cancel: cancel}
+	err := c.SetUp(context.WithValue(ctx, agent.REPORTERCTX, rep))
+
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("SetUp() = %v, want context.Canceled", err)
+	}
+	var pme *agent.PartialMoveError
+	if !errors.As(err, &pme) || pme.Completed != 2 || pme.At != (agent.Point{Y: 2}) {
+		t.Fatalf("SetUp() = %v, want the first walk stopped after 2 paces at (0, 2)", err)
+	}
+	rep.m.Lock()
+	defer rep.m.Unlock()
+	starts, at := 0, agent.Point{}
+	for _, r := range rep.reports {
+		if r.Kind == agent.REPORTSTART {
+			starts++
+		}
+		if r.Kind == agent.REPORTPACE || r.Kind == agent.REPORTEND {
+			at = r.At
+		}
+	}
+	if starts != 1 || at != (agent.Point{Y: 2}) {
+		t.Errorf("%d actions started and the agent ended at %v, want only the first, ending at (0, 2)", starts, at)
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	for s, want := range map[string]agent.Direction{
+		"N": agent.NORTH, "S": agent.SOUTH, "E": agent.EAST, "W": agent.WEST,
+		"NE": agent.NORTHEAST, "NW": agent.NORTHWEST, "SE": agent.SOUTHEAST, "SW": agent.SOUTHWEST,
+	} {
+		if got, err := cfg.ParseDirection(s); err != nil || got != want {
+			t.Errorf("ParseDirection(%q) = %v, %v, want %v", s, got, err, want)
+		}
+	}
+	for _, s := range []string{"", "ne", "NNE", "EN", "north"} {
+		if _, err := cfg.ParseDirection(s); !errors.Is(err, agent.ErrUnknownDirection) {
+			t.Errorf("ParseDirection(%q) = %v, want %v", s, err, agent.ErrUnknownDirection)
+		}
+	}
+}
+
+func TestWaitKeepsPosition(t *testing.T) {
+	c := parse(t, `
+clock: virtual
+report: silent
+logLevel: ERROR
+walkSpeed: 1
+actions:
+    - {name: walk, direction: N, duration: 2}
+    - {name: wait, duration: 3}
+`)
+	report, err := c.Simulate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ran := report.Agents[0]
+	if want := (agent.Point{Y: 2}); ran.Position != want || ended(ran.Path) != 5*time.Second {
+		t.Fatalf("ended at %+v after %v, want %+v after 5s", ran.Position, ended(ran.Path), want)
+	}
+	if len(ran.Path.S) != 5 {
+		t.Fatalf("recorded %d steps, want 2 paces and 3 still ones", len(ran.Path.S))
+	}
+	for i, st := range ran.Path.S[2:] {
+		if _, still := ran.Path.M[i+2][agent.STILL]; st.Action != agent.WAIT || !still || st.From != st.To || st.Duration() != time.Second {
+			t.Errorf("step %d = %+v, want a STILL pace of 1s waiting at %v", i+3, st, ran.Position)
+		}
+	}
+}
+
+func TestSimulateRunsNamedAgents(t *testing.T) {
+	c := parse(t, `
+clock: virtual
+report: silent
+logLevel: ERROR
+walkSpeed: 2
+runSpeed: 5
+agents:
+    - name: hare
+      actions:
+          - {name: walk, direction: N, duration: 2}
+          - {name: run, direction: W, duration: 1}
+    - name: tortoise
+      kind: tortoise
+      walkSpeed: 1
+      actions:
+          - {name: walk, direction: E, duration: 3}
+`)
+	report, err := c.Simulate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		name      string
+		positions []agent.Point
+	}{
+		{"hare", []agent.Point{{Y: 2}, {Y: 4}, {X: 5, Y: 4}}},
+		{"tortoise", []agent.Point{{X: -1}, {X: -2}, {X: -3}}},
+	}
+	if len(report.Agents) != len(want) {
+		t.Fatalf("reported %d agents, want %d", len(report.Agents), len(want))
+	}
+	var combined []agent.Step
+	for i, w := range want {
+		ran := report.Agents[i]
+		if ran.Name != w.name || ran.Err != nil {
+			t.Fatalf("agent %d = %s with %v, want %s without an error", i, ran.Name, ran.Err, w.name)
+		}
+		if !reflect.DeepEqual(ran.Positions, w.positions) || ran.Position != w.positions[len(w.positions)-1] {
+			t.Errorf("%s went through %v to %v, want %v", ran.Name, ran.Positions, ran.Position, w.positions)
+		}
+		// the agents move at once: both start at the epoch and are done 3s later
+		if start := ran.Path.S[0].Start; !start.Equal(cfg.VIRTUALEPOCH) || ended(ran.Path) != 3*time.Second {
+			t.Errorf("%s moved from %v for %v, want from %v for 3s", ran.Name, start, ended(ran.Path), cfg.VIRTUALEPOCH)
+		}
+		combined = append(combined, ran.Path.S...)
+	}
+	if !reflect.DeepEqual(report.Combined.S, combined) || len(report.Combined.A) != len(combined) {
+		t.Errorf("combined %d steps, want the %d of hare then tortoise", len(report.Combined.S), len(combined))
+	}
+	for i, tr := range report.Tracks() {
+		if tr.Agent != want[i].name || tr.At != report.Agents[i].Position {
+			t.Errorf("track %d = %s at %v, want %s at %v", i, tr.Agent, tr.At, want[i].name, report.Agents[i].Position)
+		}
+	}
+}
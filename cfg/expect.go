@@ -0,0 +1,177 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dark-enstein/chardot/agent"
+	"math"
+)
+
+var ERREXPECTATION = errors.New("expectation not met") // ERREXPECTATION is wrapped by the error of every expectation a run does not meet.
+
+// DEFAULTTOLERANCE is how far the distance an agent covered may be from the one expected, when Expect sets none. A
+// Tolerance of 0 asks for the exact distance.
+const DEFAULTTOLERANCE = 0.01
+
+// Expect is what a run is expected to end with, checked once it is done: where the agent ends up, how far it went,
+// where it is after given steps, and regions it must never enter. Fields left empty are not checked.
+type Expect struct {
+	Final     *agent.Point       `yaml:"final"`
+	Distance  *float64           `yaml:"distance"`
+	Tolerance *float64           `yaml:"tolerance"` // Tolerance is how far off Distance may be, DEFAULTTOLERANCE when unset.
+	Positions []ExpectedPosition `yaml:"positions"`
+	Avoid     []agent.Bounds     `yaml:"avoid"` // Avoid are regions, as {min: {x, y}, max: {x, y}}, no pace may cross.
+}
+
+// ExpectedPosition is where an agent is expected to be after its first Step paces, the origin after none.
+type ExpectedPosition struct {
+	Step int         `yaml:"step"`
+	At   agent.Point `yaml:"at"`
+}
+
+// Outcome is the result of checking one expectation against the Track of Agent: Err is nil if it was met, and wraps
+// ERREXPECTATION otherwise.
+type Outcome struct {
+	Agent string
+	Name  string
+	Err   error
+}
+
+// Passed reports whether the expectation was met.
+func (o Outcome) Passed() bool {
+	return o.Err == nil
+}
+
+// Check checks every expectation of e against tr, and returns their Outcomes in the order of the fields of Expect.
+func (e *Expect) Check(tr agent.Track) []Outcome {
+	var outcomes []Outcome
+	outcome := func(name string, err error) {
+		if err != nil {
+			err = fmt.Errorf("%w: %s: %w", ERREXPECTATION, name, err)
+		}
+		outcomes = append(outcomes, Outcome{Agent: tr.Agent, Name: name, Err: err})
+	}
+	var steps []agent.Step
+	if tr.Path != nil {
+		steps = tr.Path.S
+	}
+
+	if e.Final != nil {
+		var err error
+		if tr.At != *e.Final {
+			err = fmt.Errorf("got (%d, %d), want (%d, %d)", tr.At.X, tr.At.Y, e.Final.X, e.Final.Y)
+		}
+		outcome("final position", err)
+	}
+	if e.Distance != nil {
+		tolerance := DEFAULTTOLERANCE
+		if e.Tolerance != nil {
+			tolerance = *e.Tolerance
+		}
+		var got float64
+		if tr.Path != nil {
+			got = tr.Path.Stats().Distance
+		}
+		var err error
+		if math.Abs(got-*e.Distance) > tolerance {
+			err = fmt.Errorf("got %.2f, want %.2f ± %v", got, *e.Distance, tolerance)
+		}
+		outcome("distance", err)
+	}
+	for _, ep := range e.Positions {
+		var err error
+		at := agent.Point{}
+		switch {
+		case ep.Step > len(steps):
+			err = fmt.Errorf("the path has only %d steps", len(steps))
+		case ep.Step > 0:
+			at = steps[ep.Step-1].To
+		case len(steps) > 0:
+			at = steps[0].From
+		}
+		if err == nil && at != ep.At {
+			err = fmt.Errorf("got (%d, %d), want (%d, %d)", at.X, at.Y, ep.At.X, ep.At.Y)
+		}
+		outcome(fmt.Sprintf("position after step %d", ep.Step), err)
+	}
+	for _, region := range e.Avoid {
+		var err error
+		for i, st := range steps {
+			if p, ok := enters(st.From, st.To, region); ok {
+				err = fmt.Errorf("step %d entered it at (%d, %d)", i+1, p.X, p.Y)
+				break
+			}
+		}
+		outcome(fmt.Sprintf("avoid (%d, %d) to (%d, %d)", region.Min.X, region.Min.Y, region.Max.X, region.Max.Y), err)
+	}
+	return outcomes
+}
+
+// enters returns the first cell within region a pace from from to to goes through, cell after cell as a World walks
+// it, and whether there is one.
+func enters(from, to agent.Point, region agent.Bounds) (agent.Point, bool) {
+	dx, dy := to.X-from.X, to.Y-from.Y
+	n := abs(dx)
+	if abs(dy) > n {
+		n = abs(dy)
+	}
+	for i := agent.Coordinate(1); i <= n; i++ {
+		x, y := i, i
+		if x > abs(dx) {
+			x = abs(dx)
+		}
+		if y > abs(dy) {
+			y = abs(dy)
+		}
+		p := agent.Point{X: from.X + sign(dx)*x, Y: from.Y + sign(dy)*y}
+		if region.Contains(p) {
+			return p, true
+		}
+	}
+	return agent.Point{}, false
+}
+
+func abs(c agent.Coordinate) agent.Coordinate {
+	if c < 0 {
+		return -c
+	}
+	return c
+}
+
+func sign(c agent.Coordinate) agent.Coordinate {
+	switch {
+	case c < 0:
+		return -1
+	case c > 0:
+		return 1
+	}
+	return 0
+}
+
+// Verify checks the expectations of the configuration against the Tracks of report: the top-level Expect against
+// every agent, then the Expect of each agent against its own.
+func (c *Config) Verify(report *agent.SimulationReport) []Outcome {
+	var outcomes []Outcome
+	for _, tr := range report.Tracks() {
+		if c.Expect != nil {
+			outcomes = append(outcomes, c.Expect.Check(tr)...)
+		}
+		for _, ac := range c.Agents {
+			if ac.Name == tr.Agent && ac.Expect != nil {
+				outcomes = append(outcomes, ac.Expect.Check(tr)...)
+			}
+		}
+	}
+	return outcomes
+}
+
+// Failures returns the errors of the Outcomes that did not pass, joined, or nil.
+func Failures(outcomes []Outcome) error {
+	var errs []error
+	for _, o := range outcomes {
+		if !o.Passed() {
+			errs = append(errs, fmt.Errorf("agent %s: %w", o.Agent, o.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,76 @@
+package cfg_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/cfg"
+)
+
+// track returns the Track of an agent that paces from the first of points to each of the others in turn.
+func track(points ...agent.Point) agent.Track {
+	path := &agent.Path{}
+	for i := 1; i < len(points); i++ {
+		path.S = append(path.S, agent.Step{Seq: i - 1, Action: agent.WALK, From: points[i-1], To: points[i]})
+	}
+	path.A = make([]agent.Pace, len(path.S))
+	tr := agent.Track{Agent: "hare", Path: path}
+	if len(points) > 0 {
+		tr.At = points[len(points)-1]
+	}
+	return tr
+}
+
+func float(f float64) *float64 {
+	return &f
+}
+
+func TestExpectCheck(t *testing.T) {
+	// a pace north, then one north-west that cuts through (1, 4), (2, 5) and (3, 5) before it ends on (4, 5)
+	path := track(agent.Point{}, agent.Point{Y: 3}, agent.Point{X: 4, Y: 5})
+	at := func(x, y agent.Coordinate) *agent.Point { return &agent.Point{X: x, Y: y} }
+	region := func(x, y agent.Coordinate) agent.Bounds {
+		return agent.Bounds{Min: agent.Point{X: x, Y: y}, Max: agent.Point{X: x, Y: y}}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		tr     agent.Track
+		expect cfg.Expect
+		fail   string // fail is part of the error of the only outcome, empty when it passes.
+	}{
+		{"final", path, cfg.Expect{Final: at(4, 5)}, ""},
+		{"wrong final", path, cfg.Expect{Final: at(4, 4)}, "got (4, 5), want (4, 4)"},
+		{"distance", path, cfg.Expect{Distance: float(7.47)}, ""},
+		{"distance off", path, cfg.Expect{Distance: float(7.5)}, "got 7.47, want 7.50 ± 0.01"},
+		{"distance within tolerance", path, cfg.Expect{Distance: float(7.5), Tolerance: float(0.1)}, ""},
+		{"exact distance", track(agent.Point{}, agent.Point{Y: 3}), cfg.Expect{Distance: float(3), Tolerance: float(0)}, ""},
+		{"distance within the default tolerance", track(agent.Point{}, agent.Point{Y: 3}), cfg.Expect{Distance: float(3.005)}, ""},
+		{"distance off with a tolerance of 0", track(agent.Point{}, agent.Point{Y: 3}), cfg.Expect{Distance: float(3.005), Tolerance: float(0)}, "± 0"},
+		{"position at step 0", track(agent.Point{X: 2}, agent.Point{X: 2, Y: 1}), cfg.Expect{Positions: []cfg.ExpectedPosition{{Step: 0, At: agent.Point{X: 2}}}}, ""},
+		{"position at step 0 of no path", track(), cfg.Expect{Positions: []cfg.ExpectedPosition{{Step: 0}}}, ""},
+		{"position at the last step", path, cfg.Expect{Positions: []cfg.ExpectedPosition{{Step: 2, At: agent.Point{X: 4, Y: 5}}}}, ""},
+		{"position past the end", path, cfg.Expect{Positions: []cfg.ExpectedPosition{{Step: 3, At: agent.Point{X: 4, Y: 5}}}}, "the path has only 2 steps"},
+		{"avoid crossed partway", path, cfg.Expect{Avoid: []agent.Bounds{region(3, 5)}}, "step 2 entered it at (3, 5)"},
+		{"avoid beside the diagonal", path, cfg.Expect{Avoid: []agent.Bounds{region(2, 4)}}, ""},
+		{"avoid at the end", path, cfg.Expect{Avoid: []agent.Bounds{{Min: agent.Point{X: 4, Y: 5}, Max: agent.Point{X: 9, Y: 9}}}}, "step 2 entered it at (4, 5)"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			outcomes := tc.expect.Check(tc.tr)
+			if len(outcomes) != 1 {
+				t.Fatalf("Check() = %d outcomes, want 1", len(outcomes))
+			}
+			o := outcomes[0]
+			switch {
+			case tc.fail == "" && !o.Passed():
+				t.Errorf("%s failed: %v", o.Name, o.Err)
+			case tc.fail != "" && o.Passed():
+				t.Errorf("%s passed, want it to fail with %q", o.Name, tc.fail)
+			case tc.fail != "" && (!errors.Is(o.Err, cfg.ERREXPECTATION) || !strings.Contains(o.Err.Error(), tc.fail)):
+				t.Errorf("%s failed with %v, want %v and %q", o.Name, o.Err, cfg.ERREXPECTATION, tc.fail)
+			}
+		})
+	}
+}
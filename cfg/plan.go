@@ -0,0 +1,362 @@
+package cfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/internal/ilog"
+	"io"
+	"math"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// PlannedStep is an action as Plan predicts it: the paces it takes, at which Speed, from where to where, and when,
+// counted from the start of the scenario.
+type PlannedStep struct {
+	// Action is the position of the action in its list, from 1, after those of the repeats and sequences it is part
+	// of: 3[2].1 is the first action of the second round of the third. The actions of the script start with S.
+	Action     string
+	Name       string
+	Direction  string
+	Speed      agent.Speed
+	Paces      int
+	From, To   agent.Point
+	Start, End time.Duration
+	Positions  []agent.Point // Positions are where each pace ends.
+}
+
+// AgentPlan is what Plan predicts for one agent: its steps, where it ends up and after how long, and the problems it
+// runs into on the way. An agent whose action fails, as one leaving the World or walking into an obstacle does, stops
+// where that action leaves it: Stopped is set and the actions after it are not planned.
+type AgentPlan struct {
+	Agent    string
+	Steps    []PlannedStep
+	Final    agent.Point
+	Duration time.Duration
+	Problems []error
+	Stopped  bool
+}
+
+// Plan predicts the positions and timings of every configured agent from its actions and its walking and running
+// speeds, without building an agent or waiting on a clock: an agent starts at the origin, and takes one pace per
+// second, covering its Speed toward the Direction of the action. Goto and waypoints actions are planned over the
+// same legs a Hare takes, and replay actions from their recording. Problems are flagged in each AgentPlan: paces
+// leaving the World or entering an obstacle, and speeds of 0, with which a walk or run goes nowhere and a goto
+// cannot be taken. The naps of a napping hare are not predicted. Plan returns an error wrapping ERRINVALIDCONFIG
+// for a configuration that cannot be planned, such as a race.
+//
+// Repeats and sequences are planned action by action. The script of a Config with agents is planned once every agent
+// is done with its own actions, if none stopped: each parallel block starts when the last agent is done with what came
+// before it, and the script stops after a block that one of its agents stopped in, as a run does.
+func (c *Config) Plan() ([]AgentPlan, error) {
+	if c.Mode == RACEMODE {
+		return nil, invalid(fmt.Errorf("mode %v cannot be planned", RACEMODE))
+	}
+	world, err := c.ResolveWorld()
+	if err != nil {
+		return nil, invalid(fmt.Errorf("world: %w", err))
+	}
+	script, err := c.CompileScript()
+	if err != nil {
+		return nil, invalid(fmt.Errorf("script: %w", err))
+	}
+	var planners []*planner
+	stopped := false
+	for _, ac := range c.agentConfigs() {
+		if _, err := c.Compile(ac.A); err != nil {
+			return nil, invalid(fmt.Errorf("agent %s: %w", ac.Name, err))
+		}
+		p, err := c.forAgent(ac).plan(ac, world)
+		if err != nil {
+			return nil, invalid(fmt.Errorf("agent %s: %w", ac.Name, err))
+		}
+		planners = append(planners, p)
+		stopped = stopped || p.Stopped
+	}
+	if len(script) > 0 && !stopped {
+		if _, err := planScript("S", c.A, c.Sequences, planners); err != nil {
+			return nil, invalid(fmt.Errorf("script: %w", err))
+		}
+	}
+	var plans []AgentPlan
+	for _, p := range planners {
+		plans = append(plans, *p.AgentPlan)
+	}
+	return plans, nil
+}
+
+// planScript plans acts, actions of the script labelled from prefix, for planners. It reports whether the script goes
+// on after them.
+func planScript(prefix string, acts []Action, sequences map[string][]Action, planners []*planner) (bool, error) {
+	for i, a := range acts {
+		label := prefix + strconv.Itoa(i+1)
+		switch {
+		case a.Repeat > 0:
+			for r := 1; r <= a.Repeat; r++ {
+				if ok, err := planScript(fmt.Sprintf("%s[%d].", label, r), a.Actions, sequences, planners); !ok || err != nil {
+					return ok, err
+				}
+			}
+		case a.Use != "":
+			if ok, err := planScript(label+".", sequences[a.Use], sequences, planners); !ok || err != nil {
+				return ok, err
+			}
+		case a.Parallel != nil:
+			var start time.Duration
+			for _, p := range planners {
+				if p.Duration > start {
+					start = p.Duration
+				}
+			}
+			stopped := false
+			for _, p := range planners {
+				branch, ok := a.Parallel[p.Agent]
+				if !ok {
+					continue
+				}
+				p.Duration = start
+				if err := p.actions(label+".", branch); err != nil {
+					return false, fmt.Errorf("agent %s: %w", p.Agent, err)
+				}
+				stopped = stopped || p.Stopped
+			}
+			if stopped {
+				return false, nil
+			}
+		}
+	}
+	return true, nil
+}
+
+// planner follows an agent through its actions as Plan predicts them.
+type planner struct {
+	*AgentPlan
+	world     *agent.World
+	walk, run agent.Speed
+	gait      agent.Gait
+	sequences map[string][]Action
+}
+
+// plan predicts the actions of ac, the agent c is the Config of, in world.
+func (c *Config) plan(ac AgentConfig, world *agent.World) (*planner, error) {
+	quiet := &ilog.Logger{}
+	quiet.SetLevel(ilog.ERROR)
+	walk, run, err := c.ResolveSpeed(context.WithValue(context.Background(), ilog.LOGGERCTX, quiet))
+	if err != nil {
+		return nil, err
+	}
+	gait, err := c.ResolveGait()
+	if err != nil {
+		return nil, err
+	}
+	p := &planner{AgentPlan: &AgentPlan{Agent: ac.Name}, world: world, walk: *walk, run: *run, gait: gait, sequences: c.Sequences}
+	if ac.Kind == TORTOISEKIND {
+		p.run = p.walk
+	}
+	if world != nil && !world.Free(p.Final) {
+		p.stop(fmt.Errorf("%w: the origin is not free in the world", agent.ErrBlocked))
+	}
+	if err := p.actions("", ac.A); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// actions plans acts, labelling them from prefix, until the agent stops.
+func (p *planner) actions(prefix string, acts []Action) error {
+	for i, a := range acts {
+		if p.Stopped {
+			break
+		}
+		label := prefix + strconv.Itoa(i+1)
+		switch {
+		case a.Repeat > 0:
+			for r := 1; r <= a.Repeat && !p.Stopped; r++ {
+				if err := p.actions(fmt.Sprintf("%s[%d].", label, r), a.Actions); err != nil {
+					return err
+				}
+			}
+		case a.Use != "":
+			if err := p.actions(label+".", p.sequences[a.Use]); err != nil {
+				return err
+			}
+		default:
+			if err := p.action(label, a); err != nil {
+				return fmt.Errorf("action %s: %w", label, err)
+			}
+		}
+	}
+	return nil
+}
+
+// stop records err as the problem the agent stops at.
+func (p *planner) stop(err error) {
+	p.Problems = append(p.Problems, err)
+	p.Stopped = true
+}
+
+// action plans a, the action of the agent labelled n. It returns an error for an action that cannot be planned at all.
+func (p *planner) action(n string, a Action) error {
+	if a.DurationSec < 0 {
+		return fmt.Errorf("%w: %v", ERRNEGATIVEDURATION, a.DurationSec)
+	}
+	st := PlannedStep{Action: n, Name: a.Name, Direction: a.Direction, From: p.Final, To: p.Final, Start: p.Duration}
+	defer func() {
+		st.To, st.End = p.Final, p.Duration
+		p.Steps = append(p.Steps, st)
+	}()
+	switch a.Name {
+	case "wait":
+		p.Duration += time.Duration(a.DurationSec) * time.Second
+		return nil
+	case "walk", "run":
+		d, err := ParseDirection(a.Direction)
+		if err != nil {
+			return err
+		}
+		st.Speed = p.walk
+		if a.Name == "run" {
+			st.Speed = p.run
+		}
+		if st.Speed == 0 && a.DurationSec > 0 {
+			p.Problems = append(p.Problems, fmt.Errorf("%w: action %s %ss at 0 and does not move", agent.ErrInvalidSpeed, n, a.Name))
+		}
+		x, y := d.Unit()
+		for i := 0; i < a.DurationSec; i++ {
+			to := agent.Point{X: p.Final.X + x*st.Speed.Int(), Y: p.Final.Y + y*st.Speed.Int()}
+			if !p.pace(n, &st, to, time.Second) {
+				break
+			}
+		}
+		return nil
+	case "goto", "waypoints":
+		waypoints := a.Waypoints
+		if a.Name == "goto" {
+			if a.To == nil {
+				return fmt.Errorf("%w: goto", ERRNOTARGET)
+			}
+			waypoints = []agent.Point{*a.To}
+		}
+		if len(waypoints) == 0 {
+			return fmt.Errorf("%w: %s", ERRNOTARGET, a.Name)
+		}
+		p.navigate(n, &st, waypoints)
+		return nil
+	case "replay":
+		rec, err := LoadRecording(a.File, a.Track)
+		if err != nil {
+			return err
+		}
+		if err := rec.Validate(); err != nil {
+			return err
+		}
+		for i := 1; i < len(rec.Samples); i++ {
+			prev, next := rec.Samples[i-1], rec.Samples[i]
+			to := agent.Point{X: p.Final.X + next.At.X - prev.At.X, Y: p.Final.Y + next.At.Y - prev.At.Y}
+			if !p.pace(n, &st, to, next.Time.Sub(prev.Time)) {
+				break
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %q", ERRUNKNOWNACTION, a.Name)
+}
+
+// navigate plans the legs to waypoints the way a Hare takes them, with the last pace of a leg covering only what is
+// left of it.
+func (p *planner) navigate(n string, st *PlannedStep, waypoints []agent.Point) {
+	at := p.Final
+	for _, wp := range waypoints {
+		legs := agent.PlanLegs(at, wp)
+		if p.world != nil {
+			var err error
+			if legs, err = p.world.PlanLegs(at, wp); err != nil {
+				p.stop(fmt.Errorf("action %s %s: %w", n, st.Name, err))
+				return
+			}
+		}
+		for _, l := range legs {
+			speed := p.walk
+			if p.gait(l) == agent.RUN {
+				speed = p.run
+			}
+			if speed <= 0 {
+				p.stop(fmt.Errorf("%w: action %s cannot %v %d toward %v at %d", agent.ErrInvalidSpeed, n, p.gait(l), l.Distance, l.Direction, speed))
+				return
+			}
+			if speed > st.Speed {
+				st.Speed = speed
+			}
+			x, y := l.Direction.Unit()
+			for left := l.Distance; left > 0; {
+				step, tick := speed.Int(), time.Second
+				if left < step {
+					step, tick = left, time.Duration(int64(time.Second)*int64(left)/int64(speed))
+				}
+				if !p.pace(n, st, agent.Point{X: p.Final.X + x*step, Y: p.Final.Y + y*step}, tick) {
+					return
+				}
+				left -= step
+			}
+		}
+		at = wp
+	}
+}
+
+// pace moves the agent to to, taking tick, unless the World stops it on the way. It reports whether the agent moved.
+func (p *planner) pace(n string, st *PlannedStep, to agent.Point, tick time.Duration) bool {
+	if p.world != nil {
+		if err := p.world.CheckMove(p.Final, to); err != nil {
+			p.stop(fmt.Errorf("action %s %s: stopped at (%d, %d) on pace %d: %w", n, st.Name, p.Final.X, p.Final.Y, st.Paces+1, err))
+			return false
+		}
+	}
+	p.Final, p.Duration = to, p.Duration+tick
+	st.Paces++
+	st.Positions = append(st.Positions, to)
+	return true
+}
+
+// Err returns the Problems of the plan joined, or nil.
+func (ap *AgentPlan) Err() error {
+	return errors.Join(ap.Problems...)
+}
+
+// Fprint writes the plan to w: a table of its steps, where the agent ends up and when, and its problems.
+func (ap *AgentPlan) Fprint(w io.Writer) error {
+	fmt.Fprintf(w, "PLAN %s\n", ap.Agent)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tACTION\tDIRECTION\tSPEED\tPACES\tSTART\tEND\tFROM\tTO")
+	for _, st := range ap.Steps {
+		dir := st.Direction
+		if dir == "" {
+			dir = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%v\t%v\t(%d, %d)\t(%d, %d)\n", st.Action, st.Name, dir, st.Speed, st.Paces,
+			st.Start, st.End, st.From.X, st.From.Y, st.To.X, st.To.Y)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	verb := "ends"
+	if ap.Stopped {
+		verb = "stops"
+	}
+	distance := 0.0
+	at := agent.Point{}
+	for _, st := range ap.Steps {
+		for _, pos := range st.Positions {
+			distance += at.Distance(&pos)
+			at = pos
+		}
+	}
+	fmt.Fprintf(w, "%s at (%d, %d) after %v, having covered %.2f\n", verb, ap.Final.X, ap.Final.Y, ap.Duration, math.Round(distance*100)/100)
+	for _, err := range ap.Problems {
+		fmt.Fprintf(w, "problem: %v\n", err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,139 @@
+package cfg_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/cfg"
+	"gopkg.in/yaml.v3"
+)
+
+// parse reads a Config from its YAML, failing the test on an error.
+func parse(t *testing.T, data string) *cfg.Config {
+	t.Helper()
+	c := &cfg.Config{}
+	if err := yaml.Unmarshal([]byte(data), c); err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+// ended returns when the last Step of path ends, counted from the virtual epoch.
+func ended(path *agent.Path) time.Duration {
+	if len(path.S) == 0 {
+		return 0
+	}
+	return path.S[len(path.S)-1].End.Sub(cfg.VIRTUALEPOCH)
+}
+
+func TestPlanMatchesRun(t *testing.T) {
+	c := parse(t, `
+clock: virtual
+report: silent
+logLevel: ERROR
+walkSpeed: 2
+runSpeed: 5
+agents:
+    - name: hare
+      actions:
+          - {name: walk, direction: N, duration: 3}
+          - {name: wait, duration: 2}
+          - {name: run, direction: SW, duration: 2}
+          - {name: goto, to: {x: -7, y: 4}}
+    - name: tortoise
+      kind: tortoise
+      walkSpeed: 1
+      actions:
+          - repeat: 2
+            actions:
+                - {name: walk, direction: E, duration: 2}
+                - {name: run, direction: N, duration: 1}
+          - {name: waypoints, waypoints: [{x: 0, y: 0}, {x: 3, y: 3}]}
+`)
+	plans, err := c.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := c.Simulate(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plans) != len(report.Agents) {
+		t.Fatalf("planned %d agents, ran %d", len(plans), len(report.Agents))
+	}
+	for i, plan := range plans {
+		ran := report.Agents[i]
+		if plan.Agent != ran.Name {
+			t.Fatalf("plan %d is of %s, run of %s", i, plan.Agent, ran.Name)
+		}
+		if plan.Stopped || len(plan.Problems) > 0 {
+			t.Errorf("%s: planned problems %v", plan.Agent, plan.Problems)
+		}
+		if plan.Final != ran.Position {
+			t.Errorf("%s: planned to end at %+v, ended at %+v", plan.Agent, plan.Final, ran.Position)
+		}
+		if got := ended(ran.Path); plan.Duration != got {
+			t.Errorf("%s: planned to take %v, took %v", plan.Agent, plan.Duration, got)
+		}
+	}
+}
+
+func TestPlanFlagsLeavingTheWorld(t *testing.T) {
+	dir := t.TempDir()
+	world := filepath.Join(dir, "map.txt")
+	if err := os.WriteFile(world, []byte("....\n....\n...@\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	c := parse(t, `
+walkSpeed: 1
+actions:
+    - {name: walk, direction: N, duration: 5}
+    - {name: walk, direction: W, duration: 1}
+`)
+	c.World = &cfg.WorldConfig{Map: world}
+	plans, err := c.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := plans[0]
+	if !p.Stopped || len(p.Problems) != 1 || !errors.Is(p.Problems[0], agent.ErrOutOfBounds) {
+		t.Fatalf("plan stopped=%v with problems %v, want it stopped by %v", p.Stopped, p.Problems, agent.ErrOutOfBounds)
+	}
+	// the map is three rows high: the agent stops on the top row, and the second walk is not planned
+	if want := (agent.Point{Y: 2}); p.Final != want || p.Duration != 2*time.Second || len(p.Steps) != 1 {
+		t.Errorf("plan ends at %+v after %v and %d steps, want %+v after 2s and 1 step", p.Final, p.Duration, len(p.Steps), want)
+	}
+}
+
+func TestPlanFlagsSpeedsOfZero(t *testing.T) {
+	c := parse(t, `
+walkSpeed: 0
+runSpeed: 3
+actions:
+    - {name: walk, direction: N, duration: 2}
+    - {name: run, direction: N, duration: 1}
+    - {name: goto, to: {x: 0, y: 5}}
+`)
+	plans, err := c.Plan()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := plans[0]
+	if len(p.Problems) != 2 {
+		t.Fatalf("plan has problems %v, want 2", p.Problems)
+	}
+	for _, err := range p.Problems {
+		if !errors.Is(err, agent.ErrInvalidSpeed) {
+			t.Errorf("problem %v, want it to wrap %v", err, agent.ErrInvalidSpeed)
+		}
+	}
+	// the walk goes nowhere but takes its time; the goto, walked at 0, cannot be taken
+	if want := (agent.Point{Y: 3}); !p.Stopped || p.Final != want || p.Duration != 3*time.Second {
+		t.Errorf("plan stopped=%v at %+v after %v, want it stopped at %+v after 3s", p.Stopped, p.Final, p.Duration, want)
+	}
+}
@@ -0,0 +1,101 @@
+package cfg
+
+import (
+	"context"
+	"fmt"
+	"github.com/dark-enstein/chardot/agent"
+	"time"
+)
+
+const (
+	ACTIONSMODE = "actions" // ACTIONSMODE runs the action list of every agent. It is the default.
+	RACEMODE    = "race"    // RACEMODE races every agent toward the finish line set under race.
+)
+
+const (
+	HAREKIND        = "hare"     // HAREKIND is an agent.Hare. It is the default kind.
+	TORTOISEKIND    = "tortoise" // TORTOISEKIND is an agent.Tortoise.
+	NAPPINGHAREKIND = "napping"  // NAPPINGHAREKIND is an agent.NappingHare.
+)
+
+var (
+	DEFAULTRACETIMEOUT = 10 * time.Minute
+	DEFAULTNAP         = 5 * time.Second
+)
+
+// RaceConfig sets the finish line of a race: Finish units away from the start toward Direction. Racers still out
+// after Timeout seconds give up.
+type RaceConfig struct {
+	Finish     float64 `yaml:"finish"`
+	Direction  string  `yaml:"direction"`
+	TimeoutSec int     `yaml:"timeout"`
+}
+
+// SetUpKind builds the agent ac describes, of the kind it names.
+func (c *Config) SetUpKind(ctx context.Context, ac AgentConfig) (agent.Agent, error) {
+	sub := c.forAgent(ac)
+	gait, err := sub.ResolveGait()
+	if err != nil {
+		return nil, err
+	}
+	switch ac.Kind {
+	case "", HAREKIND:
+		return sub.SetUpAgent(ctx)
+	case TORTOISEKIND:
+		walkS, _, err := sub.ResolveSpeed(ctx)
+		if err != nil {
+			return nil, err
+		}
+		t, err := agent.NewTortoise(ctx, *walkS, agent.WithGait(gait))
+		if err != nil {
+			return nil, err
+		}
+		return t, nil
+	case NAPPINGHAREKIND:
+		walkS, runS, err := sub.ResolveSpeed(ctx)
+		if err != nil {
+			return nil, err
+		}
+		nap := time.Duration(ac.NapSec) * time.Second
+		if nap == 0 {
+			nap = DEFAULTNAP
+		}
+		n, err := agent.NewNappingHare(ctx, *walkS, *runS, ac.NapOdds, nap, ac.Seed, agent.WithGait(gait))
+		if err != nil {
+			return nil, err
+		}
+		return n, nil
+	}
+	return nil, fmt.Errorf("agent kind %v not recognized", ac.Kind)
+}
+
+// RunRace races every configured agent toward the finish line and returns the ranked results.
+func (c *Config) RunRace(ctx context.Context) ([]agent.Result, error) {
+	if c.Race == nil {
+		return nil, invalid(fmt.Errorf("mode %v needs a race section", RACEMODE))
+	}
+	dir, err := ParseDirection(c.Race.Direction)
+	if err != nil {
+		return nil, invalid(fmt.Errorf("race: %w", err))
+	}
+	ctx, err = c.initContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sim := agent.NewSimulation(ctx)
+	for _, ac := range c.agentConfigs() {
+		ag, err := c.SetUpKind(ctx, ac)
+		if err != nil {
+			return nil, invalid(fmt.Errorf("agent %s: %w", ac.Name, err))
+		}
+		if err := sim.Register(ac.Name, ag); err != nil {
+			return nil, invalid(err)
+		}
+	}
+	limit := time.Duration(c.Race.TimeoutSec) * time.Second
+	if limit <= 0 {
+		limit = DEFAULTRACETIMEOUT
+	}
+	race := &agent.Race{Finish: c.Race.Finish, Direction: dir, Limit: limit}
+	return race.Run(ctx, sim)
+}
@@ -0,0 +1,557 @@
+package cfg
+
+import (
+	"errors"
+	"fmt"
+	"github.com/dark-enstein/chardot/internal/ilog"
+	"gopkg.in/yaml.v3"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ERRUNKNOWNKEY  = errors.New("unknown key")                // ERRUNKNOWNKEY is wrapped by the Problem of a key the config schema does not have.
+	ERRWRONGTYPE   = errors.New("wrong type")                 // ERRWRONGTYPE is wrapped by the Problem of a value of the wrong YAML kind or type.
+	ERREMPTYCONFIG = errors.New("config file has no content") // ERREMPTYCONFIG is wrapped by the Problem of a file that is empty or only holds comments.
+)
+
+// Problem is something wrong with a config file, at Line and Column of File. Its Err wraps the sentinel error of the
+// problem, such as ERRUNKNOWNACTION or agent.ErrUnknownDirection, and the Problem itself wraps ERRINVALIDCONFIG.
+type Problem struct {
+	File         string
+	Line, Column int
+	Err          error
+}
+
+func (p *Problem) Error() string {
+	if p.Column == 0 {
+		return fmt.Sprintf("%s:%d: %v", p.File, p.Line, p.Err)
+	}
+	return fmt.Sprintf("%s:%d:%d: %v", p.File, p.Line, p.Column, p.Err)
+}
+
+func (p *Problem) Unwrap() []error {
+	return []error{ERRINVALIDCONFIG, p.Err}
+}
+
+// Problems are the problems Validate finds in a config file, in the order they appear in it, one per line.
+type Problems []*Problem
+
+func (ps Problems) Error() string {
+	lines := make([]string, len(ps))
+	for i, p := range ps {
+		lines[i] = p.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (ps Problems) Unwrap() []error {
+	errs := make([]error, len(ps))
+	for i, p := range ps {
+		errs[i] = p
+	}
+	return errs
+}
+
+// Validate walks the YAML of the config file named file, with contents data, against the config schema and returns
+// every problem it finds at once, as Problems: unknown keys, values of the wrong type, unknown action names, kinds,
+// modes and directions, negative durations, speeds that are not non-negative integers, and actions missing what
+// they need, and files that are empty or only hold comments. It returns nil for a valid file. Validate only reads
+// data; files the config refers to, such as maps and recordings, are left to Config.Check.
+func Validate(file string, data []byte) error {
+	v := &validator{file: file}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		line := 0
+		fmt.Sscanf(err.Error(), "yaml: line %d:", &line)
+		return Problems{{File: file, Line: line, Err: err}}
+	}
+	if len(doc.Content) == 0 {
+		return Problems{{File: file, Line: 1, Err: ERREMPTYCONFIG}}
+	}
+	v.config(doc.Content[0])
+	if len(v.problems) == 0 {
+		return nil
+	}
+	sort.SliceStable(v.problems, func(i, j int) bool {
+		pi, pj := v.problems[i], v.problems[j]
+		return pi.Line < pj.Line || pi.Line == pj.Line && pi.Column < pj.Column
+	})
+	return v.problems
+}
+
+// validator collects the problems of a config file as it walks its nodes. It knows the sequences and the agents the
+// file declares before it walks the actions that refer to them.
+type validator struct {
+	file       string
+	problems   Problems
+	sequences  map[string]bool
+	agentNames map[string]bool
+}
+
+// problem records err at the position of n.
+func (v *validator) problem(n *yaml.Node, err error) {
+	v.problems = append(v.problems, &Problem{File: v.file, Line: n.Line, Column: n.Column, Err: err})
+}
+
+// resolve follows n when it is an alias to the node it stands for.
+func resolve(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// mapping checks that n, the value of what, is a mapping whose keys all are in fields, and validates the value of
+// each key with its field. It returns the value of every key found, nil when n is not a mapping.
+func (v *validator) mapping(n *yaml.Node, what string, fields map[string]func(*yaml.Node)) map[string]*yaml.Node {
+	n = resolve(n)
+	if n.Kind != yaml.MappingNode {
+		v.problem(n, fmt.Errorf("%w: %s must be a mapping", ERRWRONGTYPE, what))
+		return nil
+	}
+	found := make(map[string]*yaml.Node, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], n.Content[i+1]
+		field, ok := fields[key.Value]
+		switch {
+		case !ok:
+			v.problem(key, fmt.Errorf("%w %q in %s", ERRUNKNOWNKEY, key.Value, what))
+			continue
+		case found[key.Value] != nil:
+			v.problem(key, fmt.Errorf("duplicate key %q in %s", key.Value, what))
+			continue
+		}
+		found[key.Value] = resolve(val)
+		field(resolve(val))
+	}
+	return found
+}
+
+// sequence checks that n, the value of what, is a sequence, and validates each of its items with item.
+func (v *validator) sequence(n *yaml.Node, what string, item func(i int, n *yaml.Node)) {
+	if n.Kind != yaml.SequenceNode {
+		v.problem(n, fmt.Errorf("%w: %s must be a list", ERRWRONGTYPE, what))
+		return
+	}
+	for i, it := range n.Content {
+		item(i, resolve(it))
+	}
+}
+
+// scalar checks that n, the value of key, is a scalar, and returns it.
+func (v *validator) scalar(n *yaml.Node, key string) (string, bool) {
+	if n.Kind != yaml.ScalarNode {
+		v.problem(n, fmt.Errorf("%w: %s must be a single value", ERRWRONGTYPE, key))
+		return "", false
+	}
+	return n.Value, true
+}
+
+// check returns a field that validates the scalar value of key with fn.
+func (v *validator) check(key string, fn func(s string) error) func(*yaml.Node) {
+	return func(n *yaml.Node) {
+		if s, ok := v.scalar(n, key); ok {
+			if err := fn(s); err != nil {
+				v.problem(n, err)
+			}
+		}
+	}
+}
+
+// str is a field taking any scalar.
+func (v *validator) str(key string) func(*yaml.Node) {
+	return v.check(key, func(string) error { return nil })
+}
+
+// boolean is a field taking true or false.
+func (v *validator) boolean(key string) func(*yaml.Node) {
+	return func(n *yaml.Node) {
+		if _, ok := v.scalar(n, key); ok && n.ShortTag() != "!!bool" {
+			v.problem(n, fmt.Errorf("%w: %s must be true or false, not %q", ERRWRONGTYPE, key, n.Value))
+		}
+	}
+}
+
+// integer is a field taking an integer no less than min.
+func (v *validator) integer(key string, min int) func(*yaml.Node) {
+	return v.check(key, func(s string) error {
+		i, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be an integer, not %q", ERRWRONGTYPE, key, s)
+		}
+		if i < min {
+			return fmt.Errorf("%s %d is less than %d", key, i, min)
+		}
+		return nil
+	})
+}
+
+// number is a field taking a number between min and max.
+func (v *validator) number(key string, min, max float64) func(*yaml.Node) {
+	return v.check(key, func(s string) error {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be a number, not %q", ERRWRONGTYPE, key, s)
+		}
+		if f < min || f > max {
+			return fmt.Errorf("%s %v is not between %v and %v", key, f, min, max)
+		}
+		return nil
+	})
+}
+
+// positive is a field taking a number greater than 0.
+func (v *validator) positive(key string) func(*yaml.Node) {
+	return v.check(key, func(s string) error {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return fmt.Errorf("%w: %s must be a number, not %q", ERRWRONGTYPE, key, s)
+		}
+		if f <= 0 || math.IsInf(f, 0) || math.IsNaN(f) {
+			return fmt.Errorf("%s %v must be greater than 0", key, f)
+		}
+		return nil
+	})
+}
+
+func (v *validator) config(n *yaml.Node) {
+	v.declared(resolve(n))
+	v.mapping(n, "the config", map[string]func(*yaml.Node){
+		"actions":   v.actions(len(v.agentNames) == 0, len(v.agentNames) > 0),
+		"sequences": v.sequenceDefs,
+		"logLevel": v.check("logLevel", func(s string) error {
+			if _, err := ilog.NewLogger(s); err != nil {
+				return fmt.Errorf("logLevel %q not recognized, use INFO, DEBUG, ERROR or PANIC", s)
+			}
+			return nil
+		}),
+		"walkSpeed": v.speed("walkSpeed"),
+		"runSpeed":  v.speed("runSpeed"),
+		"gait":      v.gait,
+		"world": func(n *yaml.Node) {
+			found := v.mapping(n, "world", map[string]func(*yaml.Node){
+				"map":     v.str("map"),
+				"fourWay": v.boolean("fourWay"),
+			})
+			if found != nil && found["map"] == nil {
+				v.problem(n, fmt.Errorf("world needs a map"))
+			}
+		},
+		"clock": v.check("clock", func(s string) error {
+			_, err := (&Config{Clock: s}).ResolveClock()
+			return err
+		}),
+		"report": v.check("report", func(s string) error {
+			_, err := (&Config{Report: s}).ResolveReporter()
+			return err
+		}),
+		"stats": v.boolean("stats"),
+		"output": v.check("output", func(s string) error {
+			_, err := (&Config{Output: s}).ResolveOutput()
+			return err
+		}),
+		"outputFile": v.str("outputFile"),
+		"agents":     v.agents,
+		"mode": v.check("mode", func(s string) error {
+			if s != "" && s != ACTIONSMODE && s != RACEMODE {
+				return fmt.Errorf("mode %q not recognized, use %q or %q", s, ACTIONSMODE, RACEMODE)
+			}
+			return nil
+		}),
+		"expect": v.expect,
+		"race": func(n *yaml.Node) {
+			v.mapping(n, "race", map[string]func(*yaml.Node){
+				"finish":    v.positive("finish"),
+				"direction": v.direction,
+				"timeout":   v.integer("timeout", 0),
+			})
+		},
+	})
+}
+
+// speed is a field taking a non-negative integer speed, quoted or not.
+func (v *validator) speed(key string) func(*yaml.Node) {
+	return v.check(key, func(s string) error {
+		_, err := parseSpeed(key, s)
+		return err
+	})
+}
+
+func (v *validator) gait(n *yaml.Node) {
+	v.check("gait", func(s string) error {
+		_, err := (&Config{Gait: s}).ResolveGait()
+		return err
+	})(n)
+}
+
+func (v *validator) direction(n *yaml.Node) {
+	if s, ok := v.scalar(n, "direction"); ok {
+		if _, err := ParseDirection(s); err != nil {
+			v.problem(n, fmt.Errorf("%w, use N, S, E, W, NE, NW, SE or SW", err))
+		}
+	}
+}
+
+func (v *validator) agents(n *yaml.Node) {
+	names := map[string]bool{}
+	v.sequence(n, "agents", func(i int, n *yaml.Node) {
+		what := fmt.Sprintf("agent %d", i+1)
+		found := v.mapping(n, what, map[string]func(*yaml.Node){
+			"name": v.str("name"),
+			"kind": v.check("kind", func(s string) error {
+				switch s {
+				case HAREKIND, TORTOISEKIND, NAPPINGHAREKIND:
+					return nil
+				}
+				return fmt.Errorf("agent kind %q not recognized, use %q, %q or %q", s, HAREKIND, TORTOISEKIND, NAPPINGHAREKIND)
+			}),
+			"actions":   v.actions(true, false),
+			"walkSpeed": v.speed("walkSpeed"),
+			"runSpeed":  v.speed("runSpeed"),
+			"gait":      v.gait,
+			"napOdds":   v.number("napOdds", 0, 1),
+			"nap":       v.integer("nap", 0),
+			"seed":      v.integer("seed", math.MinInt),
+			"expect":    v.expect,
+		})
+		if found == nil {
+			return
+		}
+		switch name := found["name"]; {
+		case name == nil || name.Value == "":
+			v.problem(n, fmt.Errorf("%s needs a name", what))
+		case names[name.Value]:
+			v.problem(name, fmt.Errorf("agent %q is declared twice", name.Value))
+		default:
+			names[name.Value] = true
+		}
+	})
+}
+
+// declared records the names of the sequences and the agents root declares, so that actions can be checked against
+// them wherever they come in the file.
+func (v *validator) declared(root *yaml.Node) {
+	v.sequences, v.agentNames = map[string]bool{}, map[string]bool{}
+	if seqs := value(root, "sequences"); seqs != nil && seqs.Kind == yaml.MappingNode {
+		for i := 0; i < len(seqs.Content); i += 2 {
+			v.sequences[seqs.Content[i].Value] = true
+		}
+	}
+	if agents := value(root, "agents"); agents != nil && agents.Kind == yaml.SequenceNode {
+		for _, ag := range agents.Content {
+			if name := value(resolve(ag), "name"); name != nil && name.Kind == yaml.ScalarNode && name.Value != "" {
+				v.agentNames[name.Value] = true
+			}
+		}
+	}
+}
+
+// value returns the value of key in the mapping n, or nil.
+func value(n *yaml.Node, key string) *yaml.Node {
+	if n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return resolve(n.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// actions is a field taking a list of actions: plain ones, such as walk and goto, where plain is set, and parallel
+// blocks where parallel is. Repeats and uses of a sequence are taken anywhere.
+func (v *validator) actions(plain, parallel bool) func(*yaml.Node) {
+	return func(n *yaml.Node) {
+		v.sequence(n, "actions", v.action(plain, parallel))
+	}
+}
+
+// sequenceDefs validates the sequences of the config. Whether their actions fit where they are used is left to
+// Config.Check.
+func (v *validator) sequenceDefs(n *yaml.Node) {
+	if n.Kind != yaml.MappingNode {
+		v.problem(n, fmt.Errorf("%w: sequences must be a mapping", ERRWRONGTYPE))
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], resolve(n.Content[i+1])
+		v.sequence(val, "sequence "+key.Value, v.action(true, true))
+		if val.Kind == yaml.SequenceNode && len(val.Content) == 0 {
+			v.problem(val, fmt.Errorf("sequence %s is empty", key.Value))
+		}
+	}
+}
+
+// action returns the validation of the i-th action of a list, and that it has what its name needs. Where plain is not
+// set, actions other than repeat, use and parallel are misplaced, and so are parallel blocks where parallel is not.
+func (v *validator) action(plain, parallel bool) func(i int, n *yaml.Node) {
+	return func(i int, n *yaml.Node) {
+		what := fmt.Sprintf("action %d", i+1)
+		found := v.mapping(n, what, map[string]func(*yaml.Node){
+			"name": v.check("name", func(s string) error {
+				switch s {
+				case "walk", "run", "wait", "goto", "waypoints", "replay":
+					return nil
+				}
+				return fmt.Errorf("%w %q, use walk, run, wait, goto, waypoints or replay", ERRUNKNOWNACTION, s)
+			}),
+			"duration": v.check("duration", func(s string) error {
+				d, err := strconv.Atoi(s)
+				if err != nil {
+					return fmt.Errorf("%w: duration must be a whole number of seconds, not %q", ERRWRONGTYPE, s)
+				}
+				if d < 0 {
+					return fmt.Errorf("%w: %d", ERRNEGATIVEDURATION, d)
+				}
+				return nil
+			}),
+			"direction": v.direction,
+			"file":      v.str("file"),
+			"track":     v.str("track"),
+			"to":        v.point,
+			"waypoints": func(n *yaml.Node) {
+				v.sequence(n, "waypoints", func(_ int, n *yaml.Node) { v.point(n) })
+				if n.Kind == yaml.SequenceNode && len(n.Content) == 0 {
+					v.problem(n, fmt.Errorf("%w: waypoints is empty", ERRNOTARGET))
+				}
+			},
+			"repeat": v.integer("repeat", 1),
+			"actions": func(n *yaml.Node) {
+				v.actions(plain, parallel)(n)
+				if n.Kind == yaml.SequenceNode && len(n.Content) == 0 {
+					v.problem(n, fmt.Errorf("repeat has no actions"))
+				}
+			},
+			"use": v.check("use", func(s string) error {
+				if !v.sequences[s] {
+					return fmt.Errorf("%w %q", ERRUNKNOWNSEQUENCE, s)
+				}
+				return nil
+			}),
+			"parallel": v.parallel,
+		})
+		if found == nil {
+			return
+		}
+
+		var kinds []string
+		for _, key := range []string{"name", "repeat", "use", "parallel"} {
+			if found[key] != nil {
+				kinds = append(kinds, key)
+			}
+		}
+		switch {
+		case len(kinds) == 0 && found["actions"] != nil:
+			v.problem(n, fmt.Errorf("%s has actions but no repeat", what))
+			return
+		case len(kinds) == 0:
+			v.problem(n, fmt.Errorf("%s needs a name, or one of repeat, use and parallel", what))
+			return
+		case len(kinds) > 1:
+			v.problem(n, fmt.Errorf("%w: %s has %s", ERRAMBIGUOUSACTION, what, strings.Join(kinds, " and ")))
+			return
+		}
+		switch kinds[0] {
+		case "repeat":
+			if found["actions"] == nil {
+				v.problem(n, fmt.Errorf("%s repeat needs actions", what))
+			}
+			return
+		case "use":
+			return
+		case "parallel":
+			if !parallel {
+				v.problem(n, fmt.Errorf("%w: %s: parallel blocks go in the top-level actions of a config with agents", ERRMISPLACED, what))
+			}
+			return
+		}
+		name := found["name"]
+		if !plain {
+			v.problem(n, fmt.Errorf("%w: %s: with agents, top-level actions are parallel blocks, not %s", ERRMISPLACED, what, name.Value))
+			return
+		}
+		need := map[string]string{"walk": "direction", "run": "direction", "goto": "to", "waypoints": "waypoints", "replay": "file"}[name.Value]
+		if need != "" && found[need] == nil {
+			err := fmt.Errorf("%s %s needs a %s", what, name.Value, need)
+			if need == "to" || need == "waypoints" {
+				err = fmt.Errorf("%w: %s %s needs %s", ERRNOTARGET, what, name.Value, need)
+			}
+			v.problem(n, err)
+		}
+	}
+}
+
+// parallel validates a parallel block: a mapping from declared agents to the actions they run.
+func (v *validator) parallel(n *yaml.Node) {
+	if n.Kind != yaml.MappingNode {
+		v.problem(n, fmt.Errorf("%w: parallel must be a mapping of agents to actions", ERRWRONGTYPE))
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, val := n.Content[i], resolve(n.Content[i+1])
+		if !v.agentNames[key.Value] {
+			v.problem(key, fmt.Errorf("parallel: agent %q is not declared", key.Value))
+		}
+		v.actions(true, false)(val)
+	}
+}
+
+// expect validates an expect block, and that every position and region in it is complete.
+func (v *validator) expect(n *yaml.Node) {
+	v.mapping(n, "expect", map[string]func(*yaml.Node){
+		"final":     v.point,
+		"distance":  v.number("distance", 0, math.MaxFloat64),
+		"tolerance": v.number("tolerance", 0, math.MaxFloat64),
+		"positions": func(n *yaml.Node) {
+			v.sequence(n, "positions", func(i int, n *yaml.Node) {
+				v.need(n, fmt.Sprintf("position %d", i+1), map[string]func(*yaml.Node){
+					"step": v.integer("step", 0),
+					"at":   v.point,
+				})
+			})
+		},
+		"avoid": func(n *yaml.Node) {
+			v.sequence(n, "avoid", func(i int, n *yaml.Node) {
+				v.need(n, fmt.Sprintf("region %d", i+1), map[string]func(*yaml.Node){
+					"min": v.point,
+					"max": v.point,
+				})
+			})
+		},
+	})
+}
+
+// need validates the mapping n, the value of what, and that it has every key of fields.
+func (v *validator) need(n *yaml.Node, what string, fields map[string]func(*yaml.Node)) {
+	found := v.mapping(n, what, fields)
+	if found == nil {
+		return
+	}
+	for _, key := range sortedKeys(fields) {
+		if found[key] == nil {
+			v.problem(n, fmt.Errorf("%s needs %s", what, key))
+		}
+	}
+}
+
+// sortedKeys returns the keys of fields in order, so that problems come out the same every time.
+func sortedKeys(fields map[string]func(*yaml.Node)) []string {
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// point validates a point, as {x: 10, y: -4}.
+func (v *validator) point(n *yaml.Node) {
+	v.mapping(n, "a point", map[string]func(*yaml.Node){
+		"x": v.integer("x", math.MinInt),
+		"y": v.integer("y", math.MinInt),
+	})
+}
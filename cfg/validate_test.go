@@ -0,0 +1,94 @@
+package cfg_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/cfg"
+)
+
+// invalidConfig holds several problems, some of them on a single line.
+const invalidConfig = `mode: race
+walkSpeed: fast
+colour: red
+race:
+    finish: 0
+    direction: up
+actions:
+    - {name: fly, duration: 1}
+    - {name: walk, direction: N, duration: -2}
+    - {name: walk, direction: Q, duration: 1}
+`
+
+func TestValidateReportsEveryProblem(t *testing.T) {
+	err := cfg.Validate("invalid.cfg", []byte(invalidConfig))
+	var problems cfg.Problems
+	if !errors.As(err, &problems) {
+		t.Fatalf("Validate() = %v, want Problems", err)
+	}
+	want := []struct {
+		line, column int
+		is           error
+	}{
+		{2, 12, agent.ErrInvalidSpeed},
+		{3, 1, cfg.ERRUNKNOWNKEY},
+		{5, 13, nil},
+		{6, 16, agent.ErrUnknownDirection},
+		{8, 14, cfg.ERRUNKNOWNACTION},
+		{9, 44, cfg.ERRNEGATIVEDURATION},
+		{10, 31, agent.ErrUnknownDirection},
+	}
+	if len(problems) != len(want) {
+		t.Fatalf("Validate() found %d problems, want %d:\n%v", len(problems), len(want), err)
+	}
+	for i, w := range want {
+		p := problems[i]
+		if p.File != "invalid.cfg" || p.Line != w.line || p.Column != w.column {
+			t.Errorf("problem %d at %s:%d:%d, want invalid.cfg:%d:%d: %v", i+1, p.File, p.Line, p.Column, w.line, w.column, p.Err)
+		}
+		if w.is != nil && !errors.Is(p, w.is) {
+			t.Errorf("problem %d = %v, want it to wrap %v", i+1, p.Err, w.is)
+		}
+	}
+}
+
+func TestValidateWrongType(t *testing.T) {
+	err := cfg.Validate("wrong.cfg", []byte("stats: often\nactions:\n    - name: walk\n      direction: N\n      duration: [1]\n"))
+	var problems cfg.Problems
+	if !errors.As(err, &problems) || len(problems) != 2 {
+		t.Fatalf("Validate() = %v, want 2 Problems", err)
+	}
+	for i, at := range [][2]int{{1, 8}, {5, 17}} {
+		if p := problems[i]; p.Line != at[0] || p.Column != at[1] || !errors.Is(p, cfg.ERRWRONGTYPE) {
+			t.Errorf("problem %d = %v, want %v at %d:%d", i+1, p, cfg.ERRWRONGTYPE, at[0], at[1])
+		}
+	}
+}
+
+func TestRaceFinishMustBePositive(t *testing.T) {
+	for _, finish := range []string{"0", "-3"} {
+		data := "mode: race\nrace:\n    finish: " + finish + "\n    direction: N\n"
+		if err := cfg.Validate("race.cfg", []byte(data)); err == nil {
+			t.Errorf("Validate() of a finish of %s succeeded, want a Problem", finish)
+		}
+	}
+	if err := cfg.Validate("race.cfg", []byte("mode: race\nrace:\n    finish: 0.5\n    direction: N\n")); err != nil {
+		t.Errorf("Validate() of a finish of 0.5 = %v, want nil", err)
+	}
+
+	c := &cfg.Config{Mode: cfg.RACEMODE, Race: &cfg.RaceConfig{Direction: "N"}}
+	if err := c.Check(); !errors.Is(err, cfg.ERRINVALIDCONFIG) {
+		t.Errorf("Check() of a finish of 0 = %v, want %v", err, cfg.ERRINVALIDCONFIG)
+	}
+}
+
+func TestValidateRejectsEmptyFiles(t *testing.T) {
+	for _, data := range []string{"", "\n\n", "# nothing to run yet\n"} {
+		err := cfg.Validate("empty.cfg", []byte(data))
+		var problems cfg.Problems
+		if !errors.As(err, &problems) || len(problems) != 1 || !errors.Is(err, cfg.ERREMPTYCONFIG) || !errors.Is(err, cfg.ERRINVALIDCONFIG) {
+			t.Errorf("Validate(%q) = %v, want a Problem wrapping %v", data, err, cfg.ERREMPTYCONFIG)
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/cfg"
+)
+
+var exportCommand = &command{
+	name:    "export",
+	summary: "run a scenario quietly and export the path of every agent",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		format := fs.String("format", agent.JSONEXPORT, "export format: json, csv or geojson")
+		output := fs.String("output", "", "file to export paths to, stdout otherwise")
+		report := fs.String("report", cfg.SILENTREPORT, "report style while the scenario runs, to stderr: text, json or silent")
+		return func(ctx context.Context, e *env) error {
+			c, err := e.loadConfig()
+			if err != nil {
+				return err
+			}
+			c.Report, c.Output, c.OutputFile = *report, *format, *output
+			if _, err := c.ResolveOutput(); err != nil {
+				return invalid(err)
+			}
+			if c.Mode == cfg.RACEMODE {
+				return invalid(fmt.Errorf("mode %v has no tracks to export", cfg.RACEMODE))
+			}
+			rep, err := c.ResolveReporterTo(e.stderr)
+			if err != nil {
+				return invalid(err)
+			}
+			report, err := c.Simulate(context.WithValue(ctx, agent.REPORTERCTX, rep))
+			if report == nil {
+				return err
+			}
+			return errors.Join(err, c.Export(e.stdout, *format, report))
+		}
+	},
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+var planCommand = &command{
+	name:    "plan",
+	summary: "predict where every agent of a scenario goes and when, without running it",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		return func(ctx context.Context, e *env) error {
+			c, err := e.loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := c.Check(); err != nil {
+				return err
+			}
+			plans, err := c.Plan()
+			if err != nil {
+				return err
+			}
+			problems := 0
+			for i := range plans {
+				if err := plans[i].Fprint(e.stdout); err != nil {
+					return err
+				}
+				problems += len(plans[i].Problems)
+			}
+			switch {
+			case problems == 1:
+				return fmt.Errorf("1 problem predicted")
+			case problems > 1:
+				return fmt.Errorf("%d problems predicted", problems)
+			}
+			return nil
+		}
+	},
+}
@@ -1 +1,322 @@
 package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/dark-enstein/chardot/agent"
+	"github.com/dark-enstein/chardot/cfg"
+	"github.com/dark-enstein/chardot/internal/ilog"
+	"github.com/dark-enstein/chardot/internal/streams"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var VERSION = "dev" // VERSION is the version chardot reports. Release builds set it with -ldflags "-X".
+
+const (
+	EXIT_OK          = 0   // EXIT_OK is the exit code of a command that did what it was asked.
+	EXIT_FAILURE     = 1   // EXIT_FAILURE is the exit code of a scenario that failed while it ran.
+	EXIT_USAGE       = 2   // EXIT_USAGE is the exit code of an unknown command, or wrong flags or arguments.
+	EXIT_INVALID     = 3   // EXIT_INVALID is the exit code of a config file that does not validate.
+	EXIT_NOINPUT     = 4   // EXIT_NOINPUT is the exit code of a config file that is missing or cannot be read.
+	EXIT_OUTPUT      = 5   // EXIT_OUTPUT is the exit code of tracks that could not be exported.
+	EXIT_INTERRUPTED = 130 // EXIT_INTERRUPTED is the exit code of a scenario interrupted by SIGINT.
+)
+
+const (
+	DEFAULTCONFIG = ".chardot.cfg" // DEFAULTCONFIG is the config file read when --file is not passed, if it exists.
+	DEFAULTLEVEL  = "INFO"         // DEFAULTLEVEL is the log level used when neither --log_level nor the config sets one.
+)
+
+var (
+	CONFIGEXTS = []string{"cfg", "yaml", "yml"} // CONFIGEXTS are the extensions a config file may have.
+	LOGLEVELS  = []string{"INFO", "DEBUG", "ERROR", "PANIC"}
+)
+
+const (
+	HELP = `usage: chardot <command> [flags]
+
+commands:
+%s
+Run 'chardot help <command>' for the flags of a command. Without a command, chardot runs.
+Without --file, %s is read if it exists, and a demo scenario is used otherwise.
+
+Cannot use this tool? Help us improve by raising an issue here: https://github.com/dark-enstein/chardot/issues/new
+`
+)
+
+// ExitError is an error that ends chardot with Code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// exit returns err as an ExitError ending chardot with code.
+func exit(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+// ExitCode returns the code chardot exits with after err: the Code of an ExitError, or the code of the failure
+// class err falls in.
+func ExitCode(err error) int {
+	var ee *ExitError
+	switch {
+	case err == nil:
+		return EXIT_OK
+	case errors.As(err, &ee):
+		return ee.Code
+	case errors.Is(err, context.Canceled):
+		return EXIT_INTERRUPTED
+	case errors.Is(err, cfg.ERRINVALIDCONFIG):
+		return EXIT_INVALID
+	case errors.Is(err, cfg.ERREXPORT):
+		return EXIT_OUTPUT
+	}
+	return EXIT_FAILURE
+}
+
+// invalid marks err as an error in the configuration, ending chardot with EXIT_INVALID.
+func invalid(err error) error {
+	return fmt.Errorf("%w: %w", cfg.ERRINVALIDCONFIG, err)
+}
+
+// command is a subcommand of chardot. flags registers the flags it takes besides --file and --log_level, and
+// returns the function that runs it once they are parsed. A command takes no arguments after its flags, unless args
+// names them for its help; a command that does not read a config file has noFile set, and takes no --file.
+type command struct {
+	name    string
+	args    string
+	summary string
+	noFile  bool
+	flags   func(fs *flag.FlagSet) func(ctx context.Context, e *env) error
+}
+
+// commands are the subcommands of chardot, in the order help lists them.
+var commands = []*command{
+	runCommand,
+	validateCommand,
+	planCommand,
+	exportCommand,
+	testCommand,
+	versionCommand,
+}
+
+// lookup returns the command called name, or nil.
+func lookup(name string) *command {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// env is what a command runs with: where it writes, the flags every command shares, and its arguments.
+type env struct {
+	stdout, stderr io.Writer
+	file           string
+	logLevel       string
+	args           []string
+}
+
+// Execute runs the chardot command args name, with the rest of args as its flags, and returns the code chardot
+// exits with. Output goes to stdout, and errors and help asked for by mistake to stderr. Without a command, or when
+// args starts with a flag, the run command is run.
+func Execute(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	name := runCommand.name
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	} else if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		name = "help"
+		args = nil
+	}
+	if name == "help" {
+		if len(args) == 0 {
+			printHelp(stdout)
+			return EXIT_OK
+		}
+		cmd := lookup(args[0])
+		if cmd == nil {
+			fmt.Fprintf(stderr, "err: unknown command %q\n\n", args[0])
+			printHelp(stderr)
+			return EXIT_USAGE
+		}
+		fs, _, _ := newFlagSet(cmd, &env{})
+		printCommandHelp(stdout, cmd, fs)
+		return EXIT_OK
+	}
+	cmd := lookup(name)
+	if cmd == nil {
+		fmt.Fprintf(stderr, "err: unknown command %q\n\n", name)
+		printHelp(stderr)
+		return EXIT_USAGE
+	}
+
+	e := &env{stdout: stdout, stderr: stderr}
+	fs, run, shared := newFlagSet(cmd, e)
+	// flags may come after the arguments of the commands that take some
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				printCommandHelp(stdout, cmd, fs)
+				return EXIT_OK
+			}
+			fmt.Fprintf(stderr, "err: %v\n\n", err)
+			printCommandHelp(stderr, cmd, fs)
+			return EXIT_USAGE
+		}
+		if fs.NArg() == 0 || cmd.args == "" {
+			break
+		}
+		e.args, args = append(e.args, fs.Arg(0)), fs.Args()[1:]
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(stderr, "err: unexpected argument %q\n\n", fs.Arg(0))
+		printCommandHelp(stderr, cmd, fs)
+		return EXIT_USAGE
+	}
+	if shared && e.logLevel != "" && !isLogLevel(e.logLevel) {
+		fmt.Fprintf(stderr, "err: --log_level %q not recognized, use one of %s\n\n", e.logLevel, strings.Join(LOGLEVELS, ", "))
+		printCommandHelp(stderr, cmd, fs)
+		return EXIT_USAGE
+	}
+
+	err := run(ctx, e)
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			fmt.Fprintf(stderr, "err: %s\n", line)
+		}
+	}
+	return ExitCode(err)
+}
+
+// newFlagSet returns the flags of cmd, bound to e, and the function that runs cmd. shared tells whether cmd takes
+// --log_level, which every command but version does, and --file, unless noFile is set.
+func newFlagSet(cmd *command, e *env) (fs *flag.FlagSet, run func(context.Context, *env) error, shared bool) {
+	fs = flag.NewFlagSet("chardot "+cmd.name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	shared = cmd != versionCommand
+	if shared && !cmd.noFile {
+		fs.StringVar(&e.file, "file", "", "config file location")
+	}
+	if shared {
+		fs.StringVar(&e.logLevel, "log_level", "", "log level: "+strings.Join(LOGLEVELS, ", ")+"; overrides logLevel in the config file")
+	}
+	run = cmd.flags(fs)
+	return fs, run, shared
+}
+
+func printHelp(w io.Writer) {
+	var list strings.Builder
+	for _, cmd := range commands {
+		fmt.Fprintf(&list, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(w, HELP, list.String(), DEFAULTCONFIG)
+}
+
+func printCommandHelp(w io.Writer, cmd *command, fs *flag.FlagSet) {
+	usage := "chardot " + cmd.name + " [flags]"
+	if cmd.args != "" {
+		usage += " " + cmd.args
+	}
+	fmt.Fprintf(w, "usage: %s\n\n%s\n", usage, cmd.summary)
+	if cmd == runCommand {
+		fmt.Fprintln(w, "This is the default command.")
+	}
+	fmt.Fprintln(w, "\nflags:")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+	fs.SetOutput(io.Discard)
+}
+
+func isLogLevel(level string) bool {
+	for _, l := range LOGLEVELS {
+		if l == level {
+			return true
+		}
+	}
+	return false
+}
+
+// loadConfig returns the configuration the command runs: the file passed with --file, DEFAULTCONFIG if it exists,
+// or the demo scenario. A file that is missing or cannot be read yields an error ending chardot with EXIT_NOINPUT;
+// one that does not validate, the cfg.Problems found in it.
+func (e *env) loadConfig() (*cfg.Config, error) {
+	file := e.file
+	if file == "" {
+		if _, err := os.Stat(DEFAULTCONFIG); err != nil {
+			c := demoConfig()
+			e.applyLogLevel(c)
+			return c, nil
+		}
+		file = DEFAULTCONFIG
+	}
+
+	ext := strings.TrimPrefix(filepath.Ext(file), ".")
+	known := false
+	for _, x := range CONFIGEXTS {
+		known = known || ext == x
+	}
+	if !known {
+		return nil, exit(EXIT_NOINPUT, fmt.Errorf("config file %s has an invalid extension, use .%s", file, strings.Join(CONFIGEXTS, ", .")))
+	}
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, exit(EXIT_NOINPUT, fmt.Errorf("config file %s not found", file))
+	}
+	if err != nil {
+		return nil, exit(EXIT_NOINPUT, err)
+	}
+	if err := cfg.Validate(file, data); err != nil {
+		return nil, err
+	}
+	c, err := streams.YamlDecode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %w", cfg.ERRINVALIDCONFIG, file, err)
+	}
+	e.applyLogLevel(c)
+	return c, nil
+}
+
+// applyLogLevel sets the log level of c to the one passed with --log_level, if any, and to DEFAULTLEVEL if neither
+// sets one. agent.Clog, which logs what happens outside of any agent, is set to it too.
+func (e *env) applyLogLevel(c *cfg.Config) {
+	if e.logLevel != "" {
+		c.LogLevel = e.logLevel
+	}
+	if c.LogLevel == "" {
+		c.LogLevel = DEFAULTLEVEL
+	}
+	if l, err := ilog.NewLogger(c.LogLevel); err == nil {
+		agent.Clog.SetLevel(l.Level())
+	}
+}
+
+// demoConfig is the scenario chardot runs without a config file: a walk north, then a run east.
+func demoConfig() *cfg.Config {
+	w := []cfg.Action{
+		{
+			Name:        "walk",
+			DurationSec: 5,
+			Direction:   "N",
+		},
+		{
+			Name:        "run",
+			DurationSec: 50,
+			Direction:   "E",
+		},
+	}
+	return cfg.NewConfig(DEFAULTLEVEL, "5", "6", w...)
+}
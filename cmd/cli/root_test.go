@@ -0,0 +1,97 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dark-enstein/chardot/cfg"
+	"github.com/dark-enstein/chardot/cmd/cli"
+)
+
+func TestExitCode(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, cli.EXIT_OK},
+		{"failure", errors.New("walked into a wall"), cli.EXIT_FAILURE},
+		{"usage", &cli.ExitError{Code: cli.EXIT_USAGE, Err: errors.New("unknown command")}, cli.EXIT_USAGE},
+		{"no input", &cli.ExitError{Code: cli.EXIT_NOINPUT, Err: errors.New("not found")}, cli.EXIT_NOINPUT},
+		{"wrapped exit error", fmt.Errorf("run: %w", &cli.ExitError{Code: cli.EXIT_USAGE, Err: cfg.ERRINVALIDCONFIG}), cli.EXIT_USAGE},
+		{"invalid config", fmt.Errorf("agent a: %w", cfg.ERRINVALIDCONFIG), cli.EXIT_INVALID},
+		{"problems", cfg.Problems{{File: "a.cfg", Line: 1, Err: cfg.ERRUNKNOWNKEY}}, cli.EXIT_INVALID},
+		{"export", fmt.Errorf("%w: disk full", cfg.ERREXPORT), cli.EXIT_OUTPUT},
+		{"interrupted", fmt.Errorf("aborted: %w", context.Canceled), cli.EXIT_INTERRUPTED},
+		{"interrupted and failed", errors.Join(errors.New("blocked"), context.Canceled), cli.EXIT_INTERRUPTED},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := cli.ExitCode(tc.err); got != tc.want {
+				t.Errorf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestExecuteExitCodes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return file
+	}
+	valid := write("valid.cfg", "clock: virtual\nactions:\n    - {name: walk, direction: N, duration: 1}\n")
+	invalid := write("invalid.cfg", "actions:\n    - {name: fly}\n")
+	wrongExt := write("valid.txt", "actions: []\n")
+	empty := write("empty.cfg", "# nothing yet\n")
+
+	for _, tc := range []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"version", []string{"version"}, cli.EXIT_OK},
+		{"help", []string{"help"}, cli.EXIT_OK},
+		{"validate", []string{"validate", "--file", valid}, cli.EXIT_OK},
+		{"unknown command", []string{"fly"}, cli.EXIT_USAGE},
+		{"unknown flag", []string{"validate", "--bogus"}, cli.EXIT_USAGE},
+		{"bad log level", []string{"validate", "--file", valid, "--log_level", "LOUD"}, cli.EXIT_USAGE},
+		{"invalid config", []string{"validate", "--file", invalid}, cli.EXIT_INVALID},
+		{"empty config", []string{"validate", "--file", empty}, cli.EXIT_INVALID},
+		{"missing file", []string{"validate", "--file", filepath.Join(dir, "missing.cfg")}, cli.EXIT_NOINPUT},
+		{"wrong extension", []string{"validate", "--file", wrongExt}, cli.EXIT_NOINPUT},
+		{"export to a directory", []string{"export", "--file", valid, "--log_level", "ERROR", "--output", dir}, cli.EXIT_OUTPUT},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			if got := cli.Execute(context.Background(), tc.args, &stdout, &stderr); got != tc.want {
+				t.Errorf("Execute(%q) = %d, want %d\nstderr: %s", tc.args, got, tc.want, stderr.String())
+			}
+		})
+	}
+
+	var stdout, stderr bytes.Buffer
+	args := []string{"run", "--file", valid, "--log_level", "ERROR", "--report", "silent", "--output-format", "json"}
+	if got := cli.Execute(context.Background(), args, &stdout, &stderr); got != cli.EXIT_OK {
+		t.Fatalf("Execute(%q) = %d, want %d\nstderr: %s", args, got, cli.EXIT_OK, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"tracks"`) {
+		t.Errorf("run --output-format json wrote no tracks to stdout:\n%s", stdout.String())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	stdout.Reset()
+	stderr.Reset()
+	if got := cli.Execute(ctx, []string{"run", "--file", valid, "--log_level", "ERROR", "--report", "silent"}, &stdout, &stderr); got != cli.EXIT_INTERRUPTED {
+		t.Errorf("Execute() of a cancelled run = %d, want %d\nstderr: %s", got, cli.EXIT_INTERRUPTED, stderr.String())
+	}
+}
@@ -0,0 +1,42 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"github.com/dark-enstein/chardot/agent"
+)
+
+var runCommand = &command{
+	name:    "run",
+	summary: "run a scenario and report what its agents do",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		report := fs.String("report", "", "report style: text, json or silent; overrides report in the config file")
+		stats := fs.Bool("stats", false, "report the distance, displacement, bounds, time and speed of every agent after the run")
+		output := fs.String("output-format", "", "export the path of every agent after the run: json, csv or geojson")
+		outputFile := fs.String("output-file", "", "file to export paths to, stdout otherwise; alone, it exports as json")
+		return func(ctx context.Context, e *env) error {
+			c, err := e.loadConfig()
+			if err != nil {
+				return err
+			}
+			if *report != "" {
+				c.Report = *report
+			}
+			if *stats {
+				c.Stats = true
+			}
+			if *output != "" {
+				c.Output = *output
+			}
+			if *outputFile != "" {
+				c.OutputFile = *outputFile
+			}
+			c.Stdout = e.stdout
+			rep, err := c.ResolveReporterTo(e.stdout)
+			if err != nil {
+				return invalid(err)
+			}
+			return c.SetUp(context.WithValue(ctx, agent.REPORTERCTX, rep))
+		}
+	},
+}
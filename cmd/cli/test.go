@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/dark-enstein/chardot/cfg"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var testCommand = &command{
+	name:    "test",
+	args:    "[dir]",
+	summary: "run every scenario in dir, the working directory by default, and check their expect blocks",
+	noFile:  true,
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		junit := fs.String("junit", "", "also write the results as JUnit XML to this file")
+		return func(ctx context.Context, e *env) error {
+			if len(e.args) > 1 {
+				return exit(EXIT_USAGE, fmt.Errorf("test takes a single directory, got %d arguments", len(e.args)))
+			}
+			dir := "."
+			if len(e.args) == 1 {
+				dir = e.args[0]
+			}
+			files, err := scenarios(dir)
+			if err != nil {
+				return exit(EXIT_NOINPUT, err)
+			}
+
+			var results []scenarioResult
+			for _, file := range files {
+				res := runScenario(ctx, file, e.logLevel)
+				if errors.Is(res.err, context.Canceled) {
+					return res.err
+				}
+				res.fprint(e.stdout)
+				results = append(results, res)
+			}
+			passed, failed, errored := 0, 0, 0
+			for _, res := range results {
+				switch {
+				case res.invalid != nil:
+					errored++
+				case res.passed():
+					passed++
+				default:
+					failed++
+				}
+			}
+			fmt.Fprintf(e.stdout, "\n%d scenarios: %d passed, %d failed, %d errored\n", len(results), passed, failed, errored)
+
+			if *junit != "" {
+				if err := writeJUnit(*junit, dir, results); err != nil {
+					return exit(EXIT_OUTPUT, fmt.Errorf("writing %s: %w", *junit, err))
+				}
+			}
+			if failed+errored > 0 {
+				return fmt.Errorf("%d of %d scenarios did not pass", failed+errored, len(results))
+			}
+			return nil
+		}
+	},
+}
+
+// scenarios returns the config files in dir, in order of their names.
+func scenarios(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, ent := range entries {
+		ext := strings.TrimPrefix(filepath.Ext(ent.Name()), ".")
+		for _, x := range CONFIGEXTS {
+			if !ent.IsDir() && ext == x {
+				files = append(files, filepath.Join(dir, ent.Name()))
+			}
+		}
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no scenarios in %s, they end in .%s", dir, strings.Join(CONFIGEXTS, ", ."))
+	}
+	return files, nil
+}
+
+// scenarioResult is what came of running a scenario: the config could not be used (invalid), or it ran, with err,
+// and its expectations had outcomes.
+type scenarioResult struct {
+	file     string
+	invalid  error
+	err      error
+	outcomes []cfg.Outcome
+	elapsed  time.Duration
+}
+
+func (r scenarioResult) passed() bool {
+	return r.invalid == nil && r.err == nil && cfg.Failures(r.outcomes) == nil
+}
+
+// runScenario runs the scenario in file quietly: without reporting, stats or export, and logging errors only unless
+// logLevel says otherwise.
+func runScenario(ctx context.Context, file, logLevel string) scenarioResult {
+	res := scenarioResult{file: file}
+	if logLevel == "" {
+		logLevel = "ERROR"
+	}
+	le := &env{file: file, logLevel: logLevel}
+	c, err := le.loadConfig()
+	if err == nil {
+		c.Report, c.Stats, c.Output, c.OutputFile = cfg.SILENTREPORT, false, "", ""
+		err = c.Check()
+	}
+	if err != nil {
+		res.invalid = err
+		return res
+	}
+	start := time.Now()
+	res.outcomes, res.err = c.Run(ctx)
+	res.elapsed = time.Since(start)
+	if errors.Is(res.err, cfg.ERRINVALIDCONFIG) {
+		res.invalid, res.err = res.err, nil
+	}
+	return res
+}
+
+// fprint writes a line telling whether the scenario passed, followed by what went wrong.
+func (r scenarioResult) fprint(w io.Writer) {
+	var lines []string
+	status := "PASS "
+	switch {
+	case r.invalid != nil:
+		status = "ERROR"
+		lines = strings.Split(r.invalid.Error(), "\n")
+	case !r.passed():
+		status = "FAIL "
+		if r.err != nil {
+			lines = append(lines, "run: "+r.err.Error())
+		}
+		for _, o := range r.outcomes {
+			if !o.Passed() {
+				lines = append(lines, fmt.Sprintf("agent %s: %v", o.Agent, o.Err))
+			}
+		}
+	}
+	fmt.Fprintf(w, "%s %s, %d expectations (%v)\n", status, r.file, len(r.outcomes), r.elapsed.Round(time.Millisecond))
+	for _, line := range lines {
+		fmt.Fprintf(w, "      %s\n", line)
+	}
+}
+
+// junitSuites is the JUnit XML report of a test run: a testsuite per scenario, with a testcase for running it and
+// one per expectation.
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Errors   int          `xml:"errors,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Errors   int         `xml:"errors,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr,omitempty"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Error     *junitMessage `xml:"error,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// seconds formats d the way JUnit times are written.
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// writeJUnit writes the results of the scenarios in dir to file as JUnit XML.
+func writeJUnit(file, dir string, results []scenarioResult) error {
+	doc := junitSuites{Name: "chardot " + dir}
+	var total time.Duration
+	for _, r := range results {
+		class := strings.TrimSuffix(filepath.Base(r.file), filepath.Ext(r.file))
+		suite := junitSuite{Name: r.file, Time: seconds(r.elapsed)}
+		run := junitCase{Name: "run", Classname: class, Time: seconds(r.elapsed)}
+		switch {
+		case r.invalid != nil:
+			run.Error = &junitMessage{Message: "invalid scenario", Text: r.invalid.Error()}
+			suite.Errors++
+		case r.err != nil:
+			run.Failure = &junitMessage{Message: "run failed", Text: r.err.Error()}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, run)
+		for _, o := range r.outcomes {
+			c := junitCase{Name: fmt.Sprintf("agent %s: %s", o.Agent, o.Name), Classname: class}
+			if !o.Passed() {
+				c.Failure = &junitMessage{Message: o.Name, Text: o.Err.Error()}
+				suite.Failures++
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		suite.Tests = len(suite.Cases)
+		doc.Tests += suite.Tests
+		doc.Failures += suite.Failures
+		doc.Errors += suite.Errors
+		total += r.elapsed
+		doc.Suites = append(doc.Suites, suite)
+	}
+	doc.Time = seconds(total)
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, append([]byte(xml.Header), append(out, '\n')...), 0o644)
+}
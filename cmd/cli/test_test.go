@@ -0,0 +1,46 @@
+package cli_test
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/dark-enstein/chardot/cmd/cli"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+// times matches the time attributes of JUnit XML, which change from run to run.
+var times = regexp.MustCompile(`time="[0-9.]+"`)
+
+func TestJUnitGolden(t *testing.T) {
+	junit := filepath.Join(t.TempDir(), "junit.xml")
+	var stdout, stderr bytes.Buffer
+	args := []string{"test", filepath.Join("testdata", "scenarios"), "--junit", junit}
+	if got := cli.Execute(context.Background(), args, &stdout, &stderr); got != cli.EXIT_FAILURE {
+		t.Fatalf("Execute(%q) = %d, want %d\nstderr: %s", args, got, cli.EXIT_FAILURE, stderr.String())
+	}
+	data, err := os.ReadFile(junit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := times.ReplaceAll(data, []byte(`time="0.000"`))
+
+	golden := filepath.Join("testdata", "junit.golden")
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("JUnit XML differs from %s, rerun with -update if that is intended:\n%s", golden, got)
+	}
+}
@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+)
+
+var validateCommand = &command{
+	name:    "validate",
+	summary: "check a config file without running it, reporting every problem with its line and column",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		return func(ctx context.Context, e *env) error {
+			c, err := e.loadConfig()
+			if err != nil {
+				return err
+			}
+			if err := c.Check(); err != nil {
+				return err
+			}
+			fmt.Fprintln(e.stdout, "config ok")
+			return nil
+		}
+	},
+}
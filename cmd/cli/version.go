@@ -0,0 +1,19 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"runtime"
+)
+
+var versionCommand = &command{
+	name:    "version",
+	summary: "print the version of chardot",
+	flags: func(fs *flag.FlagSet) func(context.Context, *env) error {
+		return func(ctx context.Context, e *env) error {
+			fmt.Fprintf(e.stdout, "chardot %s %s/%s %s\n", VERSION, runtime.GOOS, runtime.GOARCH, runtime.Version())
+			return nil
+		}
+	},
+}
@@ -2,6 +2,8 @@ module github.com/dark-enstein/chardot
 
 go 1.20
 
+require gopkg.in/yaml.v3 v3.0.1
+
 require (
 	github.com/gdamore/encoding v1.0.0 // indirect
 	github.com/gdamore/tcell/v2 v2.6.0 // indirect
@@ -13,5 +15,4 @@ require (
 	golang.org/x/sys v0.5.0 // indirect
 	golang.org/x/term v0.5.0 // indirect
 	golang.org/x/text v0.7.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sync/atomic"
 )
 
 const (
@@ -22,23 +23,36 @@ var (
 	LOGGERCTX = "LOGGERCTX"
 )
 
+// Logger is safe for concurrent use, including changing its level while it logs.
 type Logger struct {
-	level int
+	level atomic.Int32
 }
 
 func NewLogger(level string) (*Logger, error) {
+	l := &Logger{}
 	switch level {
 	case "INFO":
-		return &Logger{level: INFO}, nil
+		l.SetLevel(INFO)
 	case "ERROR":
-		return &Logger{level: ERROR}, nil
+		l.SetLevel(ERROR)
 	case "DEBUG":
-		return &Logger{level: DEBUG}, nil
+		l.SetLevel(DEBUG)
 	case "PANIC":
-		return &Logger{level: PANIC}, nil
+		l.SetLevel(PANIC)
 	default:
 		return nil, fmt.Errorf(ERRVARNOTRECOGNIZED, VAR_LOGLEVEL)
 	}
+	return l, nil
+}
+
+// Level returns the level below which messages are dropped.
+func (l *Logger) Level() int {
+	return int(l.level.Load())
+}
+
+// SetLevel sets the level below which messages are dropped.
+func (l *Logger) SetLevel(lev int) {
+	l.level.Store(int32(lev))
 }
 
 func (l *Logger) Log(lev int, msg string, args ...interface{}) {
@@ -53,7 +67,7 @@ func (l *Logger) Log(lev int, msg string, args ...interface{}) {
 	case PANIC:
 		suffix = "panic: "
 	}
-	if lev >= l.level {
+	if lev >= l.Level() {
 		switch lev {
 		case INFO, ERROR, DEBUG:
 			log.Printf(suffix+msg, args...)
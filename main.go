@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"github.com/dark-enstein/chardot/agent"
 	"github.com/dark-enstein/chardot/cfg"
 	"github.com/dark-enstein/chardot/internal/ilog"
@@ -15,11 +16,15 @@ func main() {
 		WalkSpeed: "4",
 		RunSpeed:  "6",
 	}
-	ctx, _ := c.InitSetUp()
+	ctx, _ := c.InitSetUp(context.Background())
 	_, err := ilog.GetLoggerFromCtx(ctx)
 	ilog.CheckErrLog(err)
 	//clog.Log(ilog.PANIC, "errors encountered during init: %v", errs)
-	h := agent.NewHare(ctx, 6, 6)
+	h, err := agent.NewHare(ctx, 6, 6)
+	ilog.CheckErrLog(err)
+	if err != nil {
+		return
+	}
 
 	h.Move(4, 5)
 
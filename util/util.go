@@ -1,11 +1,24 @@
 package util
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 )
 
+// Atoi parses s, ignoring surrounding spaces, as a base 10 integer. Unlike strconv.Atoi, the error it returns
+// quotes s as it was given.
+func Atoi(s string) (int, error) {
+	i, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("%q is not an integer", s)
+	}
+	return i, nil
+}
+
+// MustAtoi is Atoi for strings known to hold an integer. It panics if s does not.
 func MustAtoi(s string) int {
-	i, err := strconv.Atoi(s)
+	i, err := Atoi(s)
 	if err != nil {
 		panic(err)
 	}